@@ -1,12 +1,23 @@
 package constant
 
+import (
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
 const (
 	// for indonesian language
 	DEVICE_LANG_ID = "ID"
 	// for english language
 	DEVICE_LANG_EN = "EN"
+	// for malay language
+	DEVICE_LANG_MS = "MS"
+	// for chinese language
+	DEVICE_LANG_ZH = "ZH"
 )
 
+// DeviceLang is the upper-cased ISO 639 code of a language, e.g. "ID".
 type DeviceLang string
 
 type DeviceUUID string
@@ -18,3 +29,23 @@ func (d DeviceLang) IsEn() bool {
 func (d DeviceLang) IsId() bool {
 	return d == DEVICE_LANG_ID
 }
+
+// Is reports whether d is lang, ignoring case.
+func (d DeviceLang) Is(lang DeviceLang) bool {
+	return strings.EqualFold(string(d), string(lang))
+}
+
+// Tag returns the BCP-47 tag of d, language.Und when d is not a language.
+func (d DeviceLang) Tag() language.Tag {
+	tag, err := language.Parse(string(d))
+	if err != nil {
+		return language.Und
+	}
+	return tag
+}
+
+// DeviceLangFromTag returns the DeviceLang of the language of tag, e.g. "ID" for id-ID.
+func DeviceLangFromTag(tag language.Tag) DeviceLang {
+	base, _ := tag.Base()
+	return DeviceLang(strings.ToUpper(base.String()))
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 
 	"go.elastic.co/apm/v2"
 )
@@ -26,6 +25,9 @@ type ErrorNews struct {
 	HttpStatus string `json:"http_status"`
 	GrpcStatus string `json:"grpc_status"`
 	Message    string `json:"error_message"`
+	Cause      string `json:"cause,omitempty"`
+
+	cause error
 }
 
 func (ar *APMReporter) Report(ctx context.Context) {
@@ -46,10 +48,13 @@ func MakeErrorNews(e *Error) *ErrorNews {
 			Message:    "unknown error",
 		}
 	}
+	httpStatus := e.httpStatus()
 	return &ErrorNews{
-		HttpStatus: fmt.Sprintf("[%d]-%s", e.StatusCode, http.StatusText(e.StatusCode)),
+		HttpStatus: fmt.Sprintf("[%d]-%s", httpStatus, StatusText(httpStatus)),
 		GrpcStatus: fmt.Sprintf("[%d]-%s", e.GrpcCode(), e.GrpcCode().String()),
 		Message:    e.ErrorMessage,
+		Cause:      e.causeChain(),
+		cause:      e.cause,
 	}
 
 }
@@ -57,3 +62,8 @@ func (e *ErrorNews) Error() string {
 	byt, _ := json.Marshal(e)
 	return string(byt)
 }
+
+// Unwrap returns the cause of the reported error, so APM records the cause chain.
+func (e *ErrorNews) Unwrap() error {
+	return e.cause
+}
@@ -6,20 +6,17 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/LukmanulHakim18/core/constant"
 	meta "github.com/LukmanulHakim18/core/metadata"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
 
 	errDetails "google.golang.org/genproto/googleapis/rpc/errdetails"
 )
 
-type Message struct {
-	English   string `json:"en"`
-	Indonesia string `json:"id"`
-}
-
 type Error struct {
 	DeviceLang       constant.DeviceLang `json:"-"`
 	StatusCode       int                 `json:"-"`
@@ -29,6 +26,13 @@ type Error struct {
 	LocalizedMessage Message             `json:"localized_message"`
 	Data             []Data              `json:"data,omitempty"`
 	ErrorData        []Data              `json:"error_data,omitempty"`
+	FieldViolations  []FieldViolation    `json:"field_violations,omitempty"`
+	// Retryable marks the error safe to retry, after RetryAfter when set
+	Retryable  bool          `json:"-"`
+	RetryAfter time.Duration `json:"-"`
+
+	cause error
+	stack []uintptr
 }
 type Data struct {
 	Key   string `json:"key,omitempty"`
@@ -46,60 +50,60 @@ type LocalizedText struct {
 }
 
 func (e *Error) Error() string {
-	if e.DeviceLang == constant.DEVICE_LANG_ID {
-		return e.LocalizedMessage.Indonesia
-	}
-	return e.LocalizedMessage.English
+	return e.LocalizedMessage.Localize(string(e.DeviceLang))
 }
 
 func (e *Error) WithData(data []Data) *Error {
-	return &Error{
-		StatusCode:   e.StatusCode,
-		ErrorCode:    e.ErrorCode,
-		ErrorMessage: e.ErrorMessage,
-		LocalizedMessage: Message{
-			English:   e.LocalizedMessage.English,
-			Indonesia: e.LocalizedMessage.Indonesia,
-		},
-		Data: data,
+	err := e.clone()
+	err.Data = data
+	if err.stack == nil {
+		err.stack = callers()
 	}
+	return err
 }
 
 func (e *Error) WithErrorData(errCode string, data map[string]string) *Error {
-	err := &Error{
-		StatusCode:   e.StatusCode,
-		ErrorCode:    errCode,
-		ErrorMessage: e.ErrorMessage,
-		LocalizedMessage: Message{
-			English:   e.LocalizedMessage.English,
-			Indonesia: e.LocalizedMessage.Indonesia,
-		},
-	}
+	err := e.clone()
+	err.ErrorCode = errCode
+	err.ErrorData = nil
 	for k, v := range data {
 		err.ErrorData = append(err.ErrorData, Data{
 			Key:   k,
 			Value: v,
 		})
 	}
+	if err.stack == nil {
+		err.stack = callers()
+	}
 
 	return err
 
 }
 
 func (e *Error) WithParameter(parameter ...interface{}) *Error {
-	return &Error{
-		StatusCode:   e.StatusCode,
-		ErrorCode:    e.ErrorCode,
-		ErrorMessage: fmt.Sprintf(e.ErrorMessage, parameter...),
-		LocalizedMessage: Message{
-			English:   fmt.Sprintf(e.LocalizedMessage.English, parameter...),
-			Indonesia: fmt.Sprintf(e.LocalizedMessage.Indonesia, parameter...),
-		},
+	err := e.clone()
+	err.ErrorMessage = fmt.Sprintf(e.ErrorMessage, parameter...)
+	err.LocalizedMessage = e.LocalizedMessage.sprintf(parameter...)
+	if err.stack == nil {
+		err.stack = callers()
 	}
+	return err
 }
 
 func (e Error) WithField(field string) *Error {
 	e.ErrorField = field
+	if e.stack == nil {
+		e.stack = callers()
+	}
+	return &e
+}
+
+// WithLocalizedMessage returns a copy of the error with the message of a BCP-47 locale set.
+func (e Error) WithLocalizedMessage(locale, message string) *Error {
+	e.LocalizedMessage = e.LocalizedMessage.With(locale, message)
+	if e.stack == nil {
+		e.stack = callers()
+	}
 	return &e
 }
 
@@ -111,6 +115,7 @@ func NewError(code, message, english, indonesia string) *Error {
 			English:   english,
 			Indonesia: indonesia,
 		},
+		stack: callers(),
 	}
 }
 
@@ -123,42 +128,37 @@ func NewErrorWithStatus(status int, code, message, english, indonesia string) *E
 			English:   english,
 			Indonesia: indonesia,
 		},
+		stack: callers(),
 	}
 }
 
 func GetInvalidParameterMessage(err *Error, parameter ...interface{}) *Error {
 	return &Error{
-		StatusCode:   err.StatusCode,
-		ErrorCode:    err.ErrorCode,
-		ErrorMessage: fmt.Sprintf(err.ErrorMessage, parameter),
-		LocalizedMessage: Message{
-			English:   fmt.Sprintf(err.LocalizedMessage.English, parameter),
-			Indonesia: fmt.Sprintf(err.LocalizedMessage.Indonesia, parameter),
-		},
+		StatusCode:       err.StatusCode,
+		ErrorCode:        err.ErrorCode,
+		ErrorMessage:     fmt.Sprintf(err.ErrorMessage, parameter),
+		LocalizedMessage: err.LocalizedMessage.sprintf(parameter),
+		stack:            callers(),
 	}
 }
 
 func GetCustomMessageWithParameters(err *Error, paramID, paramEN string) *Error {
 	return &Error{
-		StatusCode:   err.StatusCode,
-		ErrorCode:    err.ErrorCode,
-		ErrorMessage: fmt.Sprintf(err.ErrorMessage, paramEN),
-		LocalizedMessage: Message{
-			English:   fmt.Sprintf(err.LocalizedMessage.English, paramEN),
-			Indonesia: fmt.Sprintf(err.LocalizedMessage.Indonesia, paramID),
-		},
+		StatusCode:       err.StatusCode,
+		ErrorCode:        err.ErrorCode,
+		ErrorMessage:     fmt.Sprintf(err.ErrorMessage, paramEN),
+		LocalizedMessage: err.LocalizedMessage.sprintf(paramEN).With(localeIndonesia, fmt.Sprintf(err.LocalizedMessage.Indonesia, paramID)),
+		stack:            callers(),
 	}
 }
 
 func GetCustomMessageWithArgs(err *Error, args ...interface{}) *Error {
 	return &Error{
-		StatusCode:   err.StatusCode,
-		ErrorCode:    err.ErrorCode,
-		ErrorMessage: fmt.Sprintf(err.ErrorMessage, args...),
-		LocalizedMessage: Message{
-			English:   fmt.Sprintf(err.LocalizedMessage.English, args...),
-			Indonesia: fmt.Sprintf(err.LocalizedMessage.Indonesia, args...),
-		},
+		StatusCode:       err.StatusCode,
+		ErrorCode:        err.ErrorCode,
+		ErrorMessage:     fmt.Sprintf(err.ErrorMessage, args...),
+		LocalizedMessage: err.LocalizedMessage.sprintf(args...),
+		stack:            callers(),
 	}
 }
 
@@ -170,34 +170,17 @@ func GetUnauthorizedAccess(errCode string) *Error {
 			English:   "user-info not exist",
 			Indonesia: "user-info tidak ditemukan",
 		},
+		stack: callers(),
 	}
 }
 
+// GrpcCode returns the gRPC code of the error. An Error always fails the call, so a
+// status without an error code, e.g. a misconfigured 200, maps to Unknown instead of OK.
 func (e Error) GrpcCode() codes.Code {
-	switch e.StatusCode {
-	case http.StatusBadRequest:
-		return codes.InvalidArgument
-	case http.StatusGatewayTimeout:
-		return codes.DeadlineExceeded
-	case http.StatusNotFound:
-		return codes.NotFound
-	case http.StatusConflict:
-		return codes.AlreadyExists
-	case http.StatusForbidden:
-		return codes.PermissionDenied
-	case http.StatusUnauthorized:
-		return codes.Unauthenticated
-	case http.StatusTooManyRequests:
-		return codes.ResourceExhausted
-	case http.StatusNotImplemented:
-		return codes.Unimplemented
-	case http.StatusServiceUnavailable:
-		return codes.Unavailable
-	case http.StatusInternalServerError:
-		return codes.Internal
-	default:
-		return codes.Unknown
+	if code := HTTPStatusToGrpcCode(e.StatusCode); code != codes.OK {
+		return code
 	}
+	return codes.Unknown
 }
 
 func (e *Error) BuildError(ctx context.Context) error {
@@ -208,16 +191,27 @@ func (e *Error) BuildError(ctx context.Context) error {
 
 	// Set Reason as ErrorCode and Domain is StatusCode
 	errorCode := &errDetails.ErrorInfo{Reason: e.ErrorCode, Domain: strconv.Itoa(e.StatusCode)}
+	if e.ErrorData != nil || e.FieldViolations != nil {
+		errorCode.Metadata = map[string]string{}
+	}
 	if e.ErrorData != nil {
 		byteData, _ := json.Marshal(e.ErrorData)
-		errorCode.Metadata = map[string]string{
-			"error_data": string(byteData),
-		}
+		errorCode.Metadata["error_data"] = string(byteData)
+	}
+	if e.FieldViolations != nil {
+		byteData, _ := json.Marshal(e.FieldViolations)
+		errorCode.Metadata["field_violations"] = string(byteData)
 	}
 
-	// set localization message for error
-	en := &errDetails.LocalizedMessage{Locale: constant.DEVICE_LANG_EN, Message: e.LocalizedMessage.English}
-	id := &errDetails.LocalizedMessage{Locale: constant.DEVICE_LANG_ID, Message: e.LocalizedMessage.Indonesia}
+	// set localization message for error, one per available locale
+	details := []protoadapt.MessageV1{
+		&errDetails.LocalizedMessage{Locale: constant.DEVICE_LANG_EN, Message: e.LocalizedMessage.English},
+		&errDetails.LocalizedMessage{Locale: constant.DEVICE_LANG_ID, Message: e.LocalizedMessage.Indonesia},
+	}
+	for _, locale := range e.LocalizedMessage.Locales()[2:] {
+		msg, _ := e.LocalizedMessage.Get(locale)
+		details = append(details, &errDetails.LocalizedMessage{Locale: locale, Message: msg})
+	}
 
 	// set data error
 	data := []*errDetails.BadRequest_FieldViolation{}
@@ -227,15 +221,27 @@ func (e *Error) BuildError(ctx context.Context) error {
 			Description: v.Value,
 		})
 	}
+	for _, v := range e.FieldViolations {
+		data = append(data, &errDetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.LocalizedMessage.Localize(string(e.DeviceLang)),
+		})
+	}
 	badRequest := &errDetails.BadRequest{
 		FieldViolations: data,
 	}
 
-	st, _ = st.WithDetails(en, id, errorCode, badRequest)
+	details = append(details, errorCode, badRequest)
+	if retryInfo := e.retryInfo(); retryInfo != nil {
+		details = append(details, retryInfo)
+	}
+	st, _ = st.WithDetails(details...)
+
+	// log the cause chain, it is never sent to the client
+	e.logCause(ctx)
 
 	// report
-	reporter := GetAPMReporter(e)
-	reporter.Report(ctx)
+	report(ctx, e)
 
 	return st.Err()
 }
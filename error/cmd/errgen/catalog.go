@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+
+// Catalog is the YAML catalog of error definitions.
+type Catalog struct {
+	Errors []Definition `yaml:"errors"`
+}
+
+// Definition is one error of the catalog. Messages are keyed by BCP-47 locale and use
+// named placeholders, e.g. "Booking {booking_id} not found".
+type Definition struct {
+	Name     string            `yaml:"name"`
+	Code     string            `yaml:"code"`
+	Status   int               `yaml:"status"`
+	Message  string            `yaml:"message"`
+	Messages map[string]string `yaml:"messages"`
+	Params   []Param           `yaml:"params"`
+}
+
+// Param is a named parameter of the constructor, string when Type is empty.
+type Param struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+}
+
+// ParseCatalog parses and validates a YAML catalog.
+func ParseCatalog(data []byte) (*Catalog, error) {
+	catalog := &Catalog{}
+	if err := yaml.Unmarshal(data, catalog); err != nil {
+		return nil, fmt.Errorf("parse catalog: %w", err)
+	}
+	if err := catalog.validate(); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+func (c *Catalog) validate() error {
+	problems := []string{}
+	names, codes := map[string]bool{}, map[string]bool{}
+	for i := range c.Errors {
+		d := &c.Errors[i]
+		for _, problem := range d.validate() {
+			problems = append(problems, fmt.Sprintf("%s: %s", d.label(i), problem))
+		}
+		if names[d.Name] {
+			problems = append(problems, fmt.Sprintf("%s: duplicate name", d.label(i)))
+		}
+		if codes[d.Code] {
+			problems = append(problems, fmt.Sprintf("%s: duplicate code %s", d.label(i), d.Code))
+		}
+		names[d.Name], codes[d.Code] = true, true
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid catalog:\n\t%s", strings.Join(problems, "\n\t"))
+	}
+	return nil
+}
+
+func (d *Definition) label(i int) string {
+	if d.Name != "" {
+		return d.Name
+	}
+	return fmt.Sprintf("errors[%d]", i)
+}
+
+// validate checks the definition and fills the params from the placeholders when not declared.
+func (d *Definition) validate() (problems []string) {
+	if !isExported(d.Name) {
+		problems = append(problems, "name must be an exported Go identifier")
+	}
+	if d.Code == "" {
+		problems = append(problems, "missing code")
+	}
+	if d.Status < 100 || d.Status > 599 {
+		problems = append(problems, fmt.Sprintf("invalid status %d", d.Status))
+	}
+	if d.Messages["en"] == "" {
+		problems = append(problems, "missing en message")
+	}
+	if d.Message == "" {
+		d.Message = d.Messages["en"]
+	}
+
+	if len(d.Params) == 0 {
+		for _, name := range placeholders(d.Message) {
+			d.Params = append(d.Params, Param{Name: name})
+		}
+	}
+	declared := map[string]bool{}
+	for i := range d.Params {
+		if d.Params[i].Type == "" {
+			d.Params[i].Type = "string"
+		}
+		if declared[d.Params[i].Name] {
+			problems = append(problems, fmt.Sprintf("duplicate param %s", d.Params[i].Name))
+		}
+		declared[d.Params[i].Name] = true
+	}
+
+	templates := map[string]string{"message": d.Message}
+	for locale, msg := range d.Messages {
+		templates[locale] = msg
+	}
+	for _, locale := range sortedKeys(templates) {
+		used := map[string]bool{}
+		for _, name := range placeholders(templates[locale]) {
+			used[name] = true
+			if !declared[name] {
+				problems = append(problems, fmt.Sprintf("%s uses unknown placeholder {%s}", locale, name))
+			}
+		}
+		for _, p := range d.Params {
+			if !used[p.Name] {
+				problems = append(problems, fmt.Sprintf("%s does not use placeholder {%s}", locale, p.Name))
+			}
+		}
+	}
+	return problems
+}
+
+// format returns the template as a format string, each placeholder becoming the
+// indexed verb of its param so translations may order them differently.
+// Templates without params are kept as is since they are never formatted.
+func (d *Definition) format(template string) string {
+	if len(d.Params) == 0 {
+		return template
+	}
+	index := map[string]int{}
+	for i, p := range d.Params {
+		index[p.Name] = i + 1
+	}
+	template = strings.ReplaceAll(template, "%", "%%")
+	return placeholderPattern.ReplaceAllStringFunc(template, func(s string) string {
+		return fmt.Sprintf("%%[%d]v", index[s[1:len(s)-1]])
+	})
+}
+
+// placeholders returns the placeholder names of a template in order of first use.
+func placeholders(template string) []string {
+	res, seen := []string{}, map[string]bool{}
+	for _, m := range placeholderPattern.FindAllStringSubmatch(template, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			res = append(res, m[1])
+		}
+	}
+	return res
+}
+
+// goName returns the Go parameter name of a snake_case placeholder, e.g. booking_id becomes bookingID.
+func goName(name string) string {
+	parts := strings.Split(name, "_")
+	for i, part := range parts {
+		if i == 0 {
+			continue
+		}
+		if upper := strings.ToUpper(part); commonInitialisms[upper] {
+			parts[i] = upper
+		} else if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+var commonInitialisms = map[string]bool{"ID": true, "URL": true, "UUID": true, "API": true, "IP": true}
+
+func isExported(name string) bool {
+	if name == "" || !unicode.IsUpper([]rune(name)[0]) {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"text/template"
+)
+
+var fileTemplate = template.Must(template.New("errors").Funcs(template.FuncMap{
+	"goName": goName,
+}).Parse(`// Code generated by errgen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	commErrors "github.com/LukmanulHakim18/core/error"
+)
+{{range .Errors}}
+// Err{{.Name}} is the {{.Code}} error.
+var Err{{.Name}} = &commErrors.Error{
+	StatusCode:   {{.Status}},
+	ErrorCode:    {{printf "%q" .Code}},
+	ErrorMessage: {{printf "%q" .ErrorMessage}},
+	LocalizedMessage: commErrors.Message{
+		English:   {{printf "%q" .English}},
+		Indonesia: {{printf "%q" .Indonesia}},
+		{{- if .Others}}
+		Others: map[string]string{
+			{{- range $locale, $msg := .Others}}
+			{{printf "%q" $locale}}: {{printf "%q" $msg}},
+			{{- end}}
+		},
+		{{- end}}
+	},
+}
+{{- if .Params}}
+
+// New{{.Name}} returns Err{{.Name}} with its message parameters.
+func New{{.Name}}({{range $i, $p := .Params}}{{if $i}}, {{end}}{{goName $p.Name}} {{$p.Type}}{{end}}) *commErrors.Error {
+	return Err{{.Name}}.WithParameter({{range $i, $p := .Params}}{{if $i}}, {{end}}{{goName $p.Name}}{{end}})
+}
+{{- end}}
+{{end}}
+func init() {
+	commErrors.MustRegister(
+		{{- range .Errors}}
+		Err{{.Name}},
+		{{- end}}
+	)
+}
+`))
+
+type fileData struct {
+	Source  string
+	Package string
+	Errors  []errorData
+}
+
+type errorData struct {
+	Definition
+	ErrorMessage string
+	English      string
+	Indonesia    string
+	Others       map[string]string
+}
+
+// Generate returns the Go source of the catalog for package pkg.
+func Generate(catalog *Catalog, pkg, source string) ([]byte, error) {
+	data := fileData{Source: source, Package: pkg}
+	for _, d := range catalog.Errors {
+		e := errorData{
+			Definition:   d,
+			ErrorMessage: d.format(d.Message),
+			Others:       map[string]string{},
+		}
+		for locale, msg := range d.Messages {
+			switch locale {
+			case "en":
+				e.English = d.format(msg)
+			case "id":
+				e.Indonesia = d.format(msg)
+			default:
+				e.Others[locale] = d.format(msg)
+			}
+		}
+		data.Errors = append(data.Errors, e)
+	}
+
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("generate: %w", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return src, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCatalog_validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		catalog string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			catalog: `
+errors:
+  - name: BookingNotFound
+    code: BK-0001
+    status: 404
+    messages:
+      en: Booking {booking_id} not found
+      id: Pesanan {booking_id} tidak ditemukan`,
+		},
+		{
+			name: "translation missing a placeholder",
+			catalog: `
+errors:
+  - name: BookingNotFound
+    code: BK-0001
+    status: 404
+    messages:
+      en: Booking {booking_id} not found
+      id: Pesanan tidak ditemukan`,
+			wantErr: "BookingNotFound: id does not use placeholder {booking_id}",
+		},
+		{
+			name: "translation with an unknown placeholder",
+			catalog: `
+errors:
+  - name: BookingNotFound
+    code: BK-0001
+    status: 404
+    messages:
+      en: Booking {booking_id} not found
+      id: Pesanan {order_id} tidak ditemukan`,
+			wantErr: "BookingNotFound: id uses unknown placeholder {order_id}",
+		},
+		{
+			name: "declared param not used",
+			catalog: `
+errors:
+  - name: BookingNotFound
+    code: BK-0001
+    status: 404
+    params:
+      - name: booking_id
+    messages:
+      en: Booking not found`,
+			wantErr: "BookingNotFound: en does not use placeholder {booking_id}",
+		},
+		{
+			name: "duplicate code",
+			catalog: `
+errors:
+  - {name: BookingNotFound, code: BK-0001, status: 404, messages: {en: Booking not found}}
+  - {name: BookingClosed, code: BK-0001, status: 409, messages: {en: Booking closed}}`,
+			wantErr: "BookingClosed: duplicate code BK-0001",
+		},
+		{
+			name:    "invalid definition",
+			catalog: `errors: [{name: bookingNotFound, status: 200000}]`,
+			wantErr: "bookingNotFound: name must be an exported Go identifier",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCatalog([]byte(tt.catalog))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ParseCatalog() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseCatalog() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	catalog, err := ParseCatalog([]byte(`
+errors:
+  - name: FareTooHigh
+    code: BK-0002
+    status: 400
+    params:
+      - {name: amount, type: int64}
+      - {name: limit_id, type: int64}
+    messages:
+      en: Fare {amount} exceeds 100% of the {limit_id} limit
+      id: Batas {limit_id} terlampaui oleh tarif {amount}
+      ms: Tambang {amount} melebihi had {limit_id}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := Generate(catalog, "booking", "errors.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"package booking",
+		`ErrorMessage: "Fare %[1]v exceeds 100%% of the %[2]v limit"`,
+		`Indonesia: "Batas %[2]v terlampaui oleh tarif %[1]v"`,
+		`"ms": "Tambang %[1]v melebihi had %[2]v"`,
+		"func NewFareTooHigh(amount int64, limitID int64) *commErrors.Error {",
+		"return ErrFareTooHigh.WithParameter(amount, limitID)",
+		"commErrors.MustRegister(\n\t\tErrFareTooHigh,\n\t)",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("Generate() missing %q in\n%s", want, src)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Command errgen generates error definitions from a YAML catalog.
+//
+// Each error of the catalog has a name, code, HTTP status and per-locale messages with
+// named placeholders, which must be the same in every locale:
+//
+//	errors:
+//	  - name: BookingNotFound
+//	    code: BK-0001
+//	    status: 404
+//	    messages:
+//	      en: Booking {booking_id} not found
+//	      id: Pesanan {booking_id} tidak ditemukan
+//
+// generates ErrBookingNotFound, a NewBookingNotFound(bookingID string) constructor and
+// registers the errors in the error registry. Params default to string and may be typed:
+//
+//	params:
+//	  - name: booking_id
+//	    type: int64
+//
+// Usage, from the package holding the catalog:
+//
+//	//go:generate go run github.com/LukmanulHakim18/core/error/cmd/errgen -in errors.yaml -out errors_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	in := flag.String("in", "errors.yaml", "YAML catalog to read")
+	out := flag.String("out", "errors_gen.go", "Go file to write")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "package of the generated file, defaults to the package running go generate")
+	flag.Parse()
+
+	if err := run(*in, *out, *pkg); err != nil {
+		fmt.Fprintln(os.Stderr, "errgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out, pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("missing -package")
+	}
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+	catalog, err := ParseCatalog(data)
+	if err != nil {
+		return err
+	}
+	src, err := Generate(catalog, pkg, filepath.Base(in))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
@@ -92,3 +92,28 @@ var UnknownMiddleware = &Error{
 		Indonesia: "middleware %v tidak ditemukan",
 	},
 }
+
+var ValidationFailed = &Error{
+	StatusCode:   http.StatusBadRequest,
+	ErrorCode:    "BB-0009",
+	ErrorMessage: "Some fields are invalid",
+	LocalizedMessage: Message{
+		English:   "Some fields are invalid",
+		Indonesia: "Beberapa data tidak sesuai",
+	},
+}
+
+func init() {
+	MustRegister(
+		UnknownErrorGateway,
+		UnknownError,
+		ErrorParse,
+		UnsupportedAppVersion,
+		ErrorDatabase,
+		MissingRequiredParam,
+		InvalidParam,
+		UnimplementedMethod,
+		UnknownMiddleware,
+		ValidationFailed,
+	)
+}
@@ -0,0 +1,202 @@
+package error
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/LukmanulHakim18/core/constant"
+	"golang.org/x/text/language"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	errDetails "google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+const (
+	ContentTypeJSON        = "application/json"
+	ContentTypeProblemJSON = "application/problem+json"
+)
+
+// ProblemTypeBaseURI is prefixed to the error code to build the problem type,
+// e.g. "https://docs.example.com/errors/" gives "https://docs.example.com/errors/BB-0005".
+// When empty the type is "about:blank".
+var ProblemTypeBaseURI = ""
+
+// ProblemDetails is the RFC 7807 representation of an Error.
+type ProblemDetails struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail"`
+	Instance      string         `json:"instance,omitempty"`
+	ErrorCode     string         `json:"error_code"`
+	ErrorData     []Data         `json:"error_data,omitempty"`
+	InvalidParams []ProblemParam `json:"invalid_params,omitempty"`
+}
+
+type ProblemParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// WriteHTTP writes err as an HTTP response. *Error and gRPC status errors keep their
+// code and status, any other error is written as UnknownError. The message is localized
+// from the Accept-Language header, and the body is problem+json when the client accepts it,
+// else the Error JSON.
+func WriteHTTP(w http.ResponseWriter, r *http.Request, err error) {
+	e := toError(err)
+	if r != nil {
+		e.logCause(r.Context())
+	}
+
+	acceptLanguage, accept := "", ""
+	if r != nil {
+		acceptLanguage, accept = r.Header.Get("Accept-Language"), r.Header.Get("Accept")
+	}
+	_, locale := e.LocalizedMessage.localizeAcceptLanguage(acceptLanguage)
+	e.DeviceLang = constant.DeviceLang(strings.ToUpper(locale))
+
+	statusCode := e.httpStatus()
+
+	var (
+		body        []byte
+		contentType = ContentTypeJSON
+	)
+	if strings.Contains(accept, ContentTypeProblemJSON) {
+		contentType = ContentTypeProblemJSON
+		problem := e.ProblemDetailsLocalized(locale, statusCode)
+		if r != nil {
+			problem.Instance = r.URL.RequestURI()
+		}
+		body, _ = json.Marshal(problem)
+	} else {
+		body, _ = json.Marshal(e)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Language", locale)
+	if retryAfter := e.retryAfterHeader(); retryAfter != "" {
+		w.Header().Set("Retry-After", retryAfter)
+	}
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
+
+// ProblemDetails returns the RFC 7807 representation of the error with detail as message,
+// and the field violations in English.
+func (e *Error) ProblemDetails(detail string, statusCode int) ProblemDetails {
+	return e.problemDetails(detail, "en", statusCode)
+}
+
+// ProblemDetailsLocalized returns the RFC 7807 representation of the error with messages in locale.
+func (e *Error) ProblemDetailsLocalized(locale string, statusCode int) ProblemDetails {
+	return e.problemDetails(e.LocalizedMessage.Localize(locale), locale, statusCode)
+}
+
+func (e *Error) problemDetails(detail, locale string, statusCode int) ProblemDetails {
+	problem := ProblemDetails{
+		Type:      "about:blank",
+		Title:     e.ErrorMessage,
+		Status:    statusCode,
+		Detail:    detail,
+		ErrorCode: e.ErrorCode,
+		ErrorData: e.ErrorData,
+	}
+	if ProblemTypeBaseURI != "" {
+		problem.Type = ProblemTypeBaseURI + e.ErrorCode
+	}
+	if problem.Title == "" {
+		problem.Title = StatusText(statusCode)
+	}
+	for _, v := range e.FieldViolations {
+		problem.InvalidParams = append(problem.InvalidParams, ProblemParam{Name: v.Field, Reason: v.LocalizedMessage.Localize(locale)})
+	}
+	if e.ErrorField != "" && len(e.FieldViolations) == 0 {
+		problem.InvalidParams = append(problem.InvalidParams, ProblemParam{Name: e.ErrorField, Reason: detail})
+	}
+	return problem
+}
+
+// FromGRPCError rebuilds the Error sent with BuildError from a gRPC status error,
+// with err as cause. It returns nil when err is not a gRPC status error.
+func FromGRPCError(err error) *Error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() == codes.OK {
+		return nil
+	}
+
+	res := &Error{
+		StatusCode:   GrpcCodeToHTTPStatus(st.Code()),
+		ErrorCode:    UnknownError.ErrorCode,
+		ErrorMessage: st.Message(),
+		cause:        err,
+	}
+	res.LocalizedMessage.English = st.Message()
+
+	var violations []Data
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errDetails.ErrorInfo:
+			res.ErrorCode = d.Reason
+			if code, err := strconv.Atoi(d.Domain); err == nil && code != 0 {
+				res.StatusCode = code
+			}
+			if data, ok := d.Metadata["error_data"]; ok {
+				json.Unmarshal([]byte(data), &res.ErrorData)
+			}
+			if data, ok := d.Metadata["field_violations"]; ok {
+				json.Unmarshal([]byte(data), &res.FieldViolations)
+			}
+		case *errDetails.LocalizedMessage:
+			res.LocalizedMessage = res.LocalizedMessage.With(d.Locale, d.Message)
+		case *errDetails.RetryInfo:
+			res.Retryable = true
+			res.RetryAfter = d.GetRetryDelay().AsDuration()
+		case *errDetails.BadRequest:
+			for _, v := range d.FieldViolations {
+				violations = append(violations, Data{Key: v.Field, Value: v.Description})
+			}
+		}
+	}
+	if res.ErrorData == nil && res.FieldViolations == nil {
+		res.ErrorData = violations
+	}
+	if res.LocalizedMessage.English != "" {
+		res.ErrorMessage = res.LocalizedMessage.English
+	}
+	return res
+}
+
+// toError returns err as *Error, converting gRPC status errors and wrapping any other error.
+func toError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.clone()
+	}
+	if e = FromGRPCError(err); e != nil {
+		return e
+	}
+	return UnknownError.Wrap(err)
+}
+
+// localizeAcceptLanguage returns the message of the most preferred locale of an
+// Accept-Language header that has a message, and that locale.
+func (m Message) localizeAcceptLanguage(header string) (string, string) {
+	tags, _, _ := language.ParseAcceptLanguage(header)
+	for _, tag := range tags {
+		if tag == language.Und {
+			continue
+		}
+		// English is appended as last resort to every chain, try the next preferred locale first
+		chain := fallbackChain(tag.String())
+		for _, locale := range chain[:len(chain)-1] {
+			if msg, ok := m.Get(locale); ok {
+				return msg, locale
+			}
+		}
+	}
+	return m.English, localeEnglish
+}
@@ -0,0 +1,125 @@
+package error
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestWriteHTTP(t *testing.T) {
+	notFound := NewErrorWithStatus(http.StatusNotFound, "BK-0001", "Booking not found", "Booking not found", "Pesanan tidak ditemukan").
+		WithLocalizedMessage("ms", "Tempahan tidak dijumpai")
+	invalid := MissingRequiredParam.WithParameter("email").WithField("email")
+
+	tests := []struct {
+		name            string
+		err             error
+		header          http.Header
+		wantStatus      int
+		wantContentType string
+		wantLanguage    string
+		wantBody        map[string]interface{}
+	}{
+		{
+			name:            "legacy JSON in Indonesian",
+			err:             notFound,
+			header:          http.Header{"Accept-Language": {"ID"}},
+			wantStatus:      http.StatusNotFound,
+			wantContentType: ContentTypeJSON,
+			wantLanguage:    "id",
+			wantBody: map[string]interface{}{
+				"error_code":        "BK-0001",
+				"error_message":     "Booking not found",
+				"localized_message": map[string]interface{}{"en": "Booking not found", "id": "Pesanan tidak ditemukan", "ms": "Tempahan tidak dijumpai"},
+			},
+		},
+		{
+			name:            "problem JSON with q-values",
+			err:             notFound,
+			header:          http.Header{"Accept": {"application/problem+json"}, "Accept-Language": {"fr;q=0.9, ms-MY;q=0.8, en;q=0.5"}},
+			wantStatus:      http.StatusNotFound,
+			wantContentType: ContentTypeProblemJSON,
+			wantLanguage:    "ms",
+			wantBody: map[string]interface{}{
+				"type":       "about:blank",
+				"title":      "Booking not found",
+				"status":     float64(http.StatusNotFound),
+				"detail":     "Tempahan tidak dijumpai",
+				"instance":   "/bookings/1?expand=true",
+				"error_code": "BK-0001",
+			},
+		},
+		{
+			name:            "problem JSON with invalid params",
+			err:             invalid,
+			header:          http.Header{"Accept": {"application/problem+json"}},
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: ContentTypeProblemJSON,
+			wantLanguage:    "en",
+			wantBody: map[string]interface{}{
+				"type":           "about:blank",
+				"title":          "email is required",
+				"status":         float64(http.StatusBadRequest),
+				"detail":         "email is required",
+				"instance":       "/bookings/1?expand=true",
+				"error_code":     "BB-0005",
+				"invalid_params": []interface{}{map[string]interface{}{"name": "email", "reason": "email is required"}},
+			},
+		},
+		{
+			name:            "gRPC status error",
+			err:             notFound.WithErrorData("BK-0001", map[string]string{"booking_id": "1"}).BuildError(context.Background()),
+			header:          http.Header{"Accept-Language": {"ms"}},
+			wantStatus:      http.StatusNotFound,
+			wantContentType: ContentTypeJSON,
+			wantLanguage:    "ms",
+			wantBody: map[string]interface{}{
+				"error_code":        "BK-0001",
+				"error_message":     "Booking not found",
+				"localized_message": map[string]interface{}{"en": "Booking not found", "id": "Pesanan tidak ditemukan", "ms": "Tempahan tidak dijumpai"},
+				"error_data":        []interface{}{map[string]interface{}{"key": "booking_id", "value": "1"}},
+			},
+		},
+		{
+			name:            "other error",
+			err:             errors.New("connection refused"),
+			wantStatus:      http.StatusInternalServerError,
+			wantContentType: ContentTypeJSON,
+			wantLanguage:    "en",
+			wantBody: map[string]interface{}{
+				"error_code":        "BB-0001",
+				"error_message":     "Unknown Error",
+				"localized_message": map[string]interface{}{"en": "Unknown Error", "id": "Masalah tidak diketahui penyebabnya"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/bookings/1?expand=true", nil)
+			for k, v := range tt.header {
+				r.Header[k] = v
+			}
+			w := httptest.NewRecorder()
+			WriteHTTP(w, r, tt.err)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("WriteHTTP() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("WriteHTTP() Content-Type = %v, want %v", got, tt.wantContentType)
+			}
+			if got := w.Header().Get("Content-Language"); got != tt.wantLanguage {
+				t.Errorf("WriteHTTP() Content-Language = %v, want %v", got, tt.wantLanguage)
+			}
+			body := map[string]interface{}{}
+			json.Unmarshal(w.Body.Bytes(), &body)
+			if !reflect.DeepEqual(body, tt.wantBody) {
+				t.Errorf("WriteHTTP() body = %v, want %v", body, tt.wantBody)
+			}
+		})
+	}
+}
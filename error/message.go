@@ -0,0 +1,164 @@
+package error
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	localeEnglish   = "en"
+	localeIndonesia = "id"
+)
+
+// FallbackChains lists, per locale, the locales tried when it has no message of its own,
+// before its parent locale. English is always the last resort,
+// e.g. "ms-MY" falls back to "ms", then "id", then "en".
+var FallbackChains = map[string][]string{
+	"ms": {localeIndonesia},
+}
+
+type Message struct {
+	English   string `json:"en"`
+	Indonesia string `json:"id"`
+	// Others holds the messages of additional locales keyed by BCP-47 tag, e.g. "ms-MY"
+	Others map[string]string `json:"-"`
+}
+
+// With returns a copy of the message with the message of locale set.
+func (m Message) With(locale, message string) Message {
+	res := m.clone()
+	switch canonicalLocale(locale) {
+	case localeEnglish:
+		res.English = message
+	case localeIndonesia:
+		res.Indonesia = message
+	default:
+		if res.Others == nil {
+			res.Others = map[string]string{}
+		}
+		res.Others[canonicalLocale(locale)] = message
+	}
+	return res
+}
+
+// Get returns the message of exactly locale.
+func (m Message) Get(locale string) (string, bool) {
+	locale = canonicalLocale(locale)
+	switch locale {
+	case localeEnglish:
+		return m.English, m.English != ""
+	case localeIndonesia:
+		return m.Indonesia, m.Indonesia != ""
+	}
+	for k, v := range m.Others {
+		if canonicalLocale(k) == locale && v != "" {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Localize returns the message of locale, following its fallback chain down to English.
+func (m Message) Localize(locale string) string {
+	for _, l := range fallbackChain(locale) {
+		if msg, ok := m.Get(l); ok {
+			return msg
+		}
+	}
+	return m.English
+}
+
+// Locales returns "en", "id" and the other locales sorted by tag.
+func (m Message) Locales() []string {
+	others := make([]string, 0, len(m.Others))
+	for k := range m.Others {
+		others = append(others, k)
+	}
+	sort.Strings(others)
+	return append([]string{localeEnglish, localeIndonesia}, others...)
+}
+
+func (m Message) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, locale := range m.Locales() {
+		msg, _ := m.Get(locale)
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(locale)
+		val, err := json.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (m *Message) UnmarshalJSON(b []byte) error {
+	messages := map[string]string{}
+	if err := json.Unmarshal(b, &messages); err != nil {
+		return err
+	}
+	res := Message{}
+	for locale, msg := range messages {
+		res = res.With(locale, msg)
+	}
+	*m = res
+	return nil
+}
+
+func (m Message) clone() Message {
+	res := Message{
+		English:   m.English,
+		Indonesia: m.Indonesia,
+	}
+	if m.Others != nil {
+		res.Others = make(map[string]string, len(m.Others))
+		for k, v := range m.Others {
+			res.Others[k] = v
+		}
+	}
+	return res
+}
+
+// sprintf formats the message of every locale with the same args.
+func (m Message) sprintf(args ...interface{}) Message {
+	res := m.clone()
+	res.English = fmt.Sprintf(m.English, args...)
+	res.Indonesia = fmt.Sprintf(m.Indonesia, args...)
+	for k, v := range res.Others {
+		res.Others[k] = fmt.Sprintf(v, args...)
+	}
+	return res
+}
+
+// canonicalLocale returns the BCP-47 form of locale, e.g. "ID" becomes "id".
+func canonicalLocale(locale string) string {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return strings.ToLower(locale)
+	}
+	return tag.String()
+}
+
+// fallbackChain returns the locales tried for locale, most specific first.
+func fallbackChain(locale string) []string {
+	chain := []string{}
+	if tag, err := language.Parse(locale); err == nil {
+		for ; !tag.IsRoot(); tag = tag.Parent() {
+			chain = append(chain, tag.String())
+			chain = append(chain, FallbackChains[tag.String()]...)
+		}
+	}
+	return append(chain, localeEnglish)
+}
@@ -0,0 +1,79 @@
+package error
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMessage_Localize(t *testing.T) {
+	msg := Message{English: "Not found", Indonesia: "Tidak ditemukan"}.
+		With("ms-MY", "Tidak dijumpai").
+		With("zh", "未找到")
+
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{locale: "EN", want: "Not found"},
+		{locale: "ID", want: "Tidak ditemukan"},
+		{locale: "id-ID", want: "Tidak ditemukan"},
+		{locale: "ms-MY", want: "Tidak dijumpai"},
+		{locale: "ms-BN", want: "Tidak ditemukan"},
+		{locale: "zh-CN", want: "未找到"},
+		{locale: "ja", want: "Not found"},
+		{locale: "", want: "Not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			if got := msg.Localize(tt.locale); got != tt.want {
+				t.Errorf("Message.Localize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessage_JSON(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  Message
+		want string
+	}{
+		{
+			name: "english and indonesia only",
+			msg:  Message{English: "Unknown Error"},
+			want: `{"en":"Unknown Error","id":""}`,
+		},
+		{
+			name: "additional locales after english and indonesia",
+			msg:  Message{English: "Not found", Indonesia: "Tidak ditemukan"}.With("ms-MY", "Tidak dijumpai"),
+			want: `{"en":"Not found","id":"Tidak ditemukan","ms-MY":"Tidak dijumpai"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			byt, err := json.Marshal(tt.msg)
+			if err != nil || string(byt) != tt.want {
+				t.Fatalf("json.Marshal() = %s, %v, want %s", byt, err, tt.want)
+			}
+			var got Message
+			if err := json.Unmarshal(byt, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Locales(), tt.msg.Locales()) || got.Localize("ms-MY") != tt.msg.Localize("ms-MY") {
+				t.Errorf("json.Unmarshal() = %+v, want %+v", got, tt.msg)
+			}
+		})
+	}
+}
+
+func TestError_WithParameterKeepsLocales(t *testing.T) {
+	err := MissingRequiredParam.WithLocalizedMessage("ms", "%v diperlukan").WithParameter("email")
+	err.DeviceLang = "ms-MY"
+	if got := err.Error(); got != "email diperlukan" {
+		t.Errorf("Error.Error() = %v, want %v", got, "email diperlukan")
+	}
+	if _, ok := MissingRequiredParam.LocalizedMessage.Get("ms"); ok {
+		t.Errorf("WithLocalizedMessage() modified the shared definition")
+	}
+}
@@ -0,0 +1,37 @@
+package error
+
+import (
+	"errors"
+
+	meta "github.com/LukmanulHakim18/core/metadata"
+)
+
+// FromMetadataError converts a *metadata.ParseError into MissingRequiredParam or
+// InvalidParam for a single key, or a ValidationFailed error with a violation per key.
+// It returns nil when err is not a *metadata.ParseError.
+func FromMetadataError(err error) *Error {
+	var parseErr *meta.ParseError
+	if !errors.As(err, &parseErr) {
+		return nil
+	}
+
+	v := NewValidationError(nil)
+	for _, key := range parseErr.Missing {
+		v.Add(key, MissingRequiredParam.WithParameter(key))
+	}
+	for _, key := range parseErr.Invalid {
+		v.Add(key, InvalidParam.WithParameter(key))
+	}
+	if len(v.violations) == 1 {
+		violation := v.violations[0]
+		if violation.ErrorCode == MissingRequiredParam.ErrorCode {
+			return MissingRequiredParam.WithParameter(violation.Field).WithField(violation.Field).Wrap(err)
+		}
+		return InvalidParam.WithParameter(violation.Field).WithField(violation.Field).Wrap(err)
+	}
+	built := v.Build()
+	if built == nil {
+		return nil
+	}
+	return built.Wrap(err)
+}
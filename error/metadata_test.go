@@ -0,0 +1,30 @@
+package error
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	meta "github.com/LukmanulHakim18/core/metadata"
+)
+
+func TestFromMetadataError(t *testing.T) {
+	single := FromMetadataError(&meta.ParseError{Missing: []string{"token"}})
+	if single.ErrorCode != MissingRequiredParam.ErrorCode || single.ErrorField != "token" || single.ErrorMessage != "token is required" {
+		t.Errorf("FromMetadataError() = %+v, want MissingRequiredParam for token", single)
+	}
+
+	err := FromMetadataError(fmt.Errorf("parse: %w", &meta.ParseError{Missing: []string{"token"}, Invalid: []string{"app-version"}}))
+	got := []string{}
+	for _, v := range err.FieldViolations {
+		got = append(got, v.Field+"="+v.ErrorCode)
+	}
+	if want := []string{"token=BB-0005", "app-version=BB-0006"}; err.ErrorCode != ValidationFailed.ErrorCode || !reflect.DeepEqual(got, want) {
+		t.Errorf("FromMetadataError() = %s %v, want %s %v", err.ErrorCode, got, ValidationFailed.ErrorCode, want)
+	}
+
+	if FromMetadataError(errors.New("other")) != nil {
+		t.Errorf("FromMetadataError() of another error is not nil")
+	}
+}
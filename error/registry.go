@@ -0,0 +1,125 @@
+package error
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Registry is a catalog of error definitions keyed by ErrorCode.
+type Registry struct {
+	mu     sync.RWMutex
+	errors map[string]*Error
+}
+
+// CatalogEntry is the exported form of a registered error.
+type CatalogEntry struct {
+	ErrorCode        string  `json:"error_code"`
+	HttpStatus       int     `json:"http_status"`
+	GrpcStatus       string  `json:"grpc_status"`
+	ErrorMessage     string  `json:"error_message"`
+	LocalizedMessage Message `json:"localized_message"`
+}
+
+// DefaultRegistry holds the errors registered with Register and MustRegister.
+var DefaultRegistry = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{
+		errors: map[string]*Error{},
+	}
+}
+
+// Register adds error definitions to the registry. Registering the same definition
+// twice is allowed, but a different definition reusing a code is rejected.
+func (r *Registry) Register(errs ...*Error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := map[string]*Error{}
+	for _, e := range errs {
+		if e == nil || e.ErrorCode == "" {
+			return fmt.Errorf("error definition without error code")
+		}
+		if registered, ok := r.errors[e.ErrorCode]; ok && registered != e {
+			return fmt.Errorf("error code %s is already registered with message %q", e.ErrorCode, registered.ErrorMessage)
+		}
+		if other, ok := seen[e.ErrorCode]; ok && other != e {
+			return fmt.Errorf("error code %s is registered twice with messages %q and %q", e.ErrorCode, other.ErrorMessage, e.ErrorMessage)
+		}
+		seen[e.ErrorCode] = e
+	}
+	for _, e := range errs {
+		r.errors[e.ErrorCode] = e
+	}
+	return nil
+}
+
+// MustRegister is like Register but panics on duplicate codes, meant to be called at init.
+func (r *Registry) MustRegister(errs ...*Error) {
+	if err := r.Register(errs...); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the error definition registered for code.
+func (r *Registry) Lookup(code string) (*Error, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.errors[code]
+	return e, ok
+}
+
+// Catalog returns every registered error sorted by code.
+func (r *Registry) Catalog() []CatalogEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := make([]CatalogEntry, 0, len(r.errors))
+	for _, e := range r.errors {
+		res = append(res, CatalogEntry{
+			ErrorCode:        e.ErrorCode,
+			HttpStatus:       e.StatusCode,
+			GrpcStatus:       e.GrpcCode().String(),
+			ErrorMessage:     e.ErrorMessage,
+			LocalizedMessage: e.LocalizedMessage,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ErrorCode < res[j].ErrorCode })
+	return res
+}
+
+// ExportJSON returns the catalog as JSON, e.g. for client apps and documentation.
+func (r *Registry) ExportJSON() ([]byte, error) {
+	return json.MarshalIndent(r.Catalog(), "", "  ")
+}
+
+// CatalogHandler serves the catalog as JSON.
+func (r *Registry) CatalogHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		byt, err := r.ExportJSON()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(byt)
+	})
+}
+
+// Register adds error definitions to the DefaultRegistry.
+func Register(errs ...*Error) error {
+	return DefaultRegistry.Register(errs...)
+}
+
+// MustRegister adds error definitions to the DefaultRegistry and panics on duplicate codes.
+func MustRegister(errs ...*Error) {
+	DefaultRegistry.MustRegister(errs...)
+}
+
+// Lookup returns the error definition registered for code in the DefaultRegistry.
+func Lookup(code string) (*Error, bool) {
+	return DefaultRegistry.Lookup(code)
+}
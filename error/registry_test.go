@@ -0,0 +1,65 @@
+package error
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	notFound := NewErrorWithStatus(http.StatusNotFound, "TS-0001", "Not found", "Not found", "Tidak ditemukan")
+	duplicate := NewErrorWithStatus(http.StatusConflict, "TS-0001", "Conflict", "Conflict", "Konflik")
+
+	r := NewRegistry()
+	if err := r.Register(notFound); err != nil {
+		t.Fatalf("Registry.Register() error = %v", err)
+	}
+	if err := r.Register(notFound); err != nil {
+		t.Errorf("Registry.Register() error = %v, want nil for the same definition", err)
+	}
+	if err := r.Register(duplicate); err == nil {
+		t.Errorf("Registry.Register() error = nil, want duplicate code error")
+	}
+	if err := NewRegistry().Register(duplicate, notFound); err == nil {
+		t.Errorf("Registry.Register() error = nil, want duplicate code error within the same call")
+	}
+	if err := NewRegistry().Register(notFound, notFound); err != nil {
+		t.Errorf("Registry.Register() error = %v, want nil for the same definition twice in a call", err)
+	}
+	if err := r.Register(&Error{}); err == nil {
+		t.Errorf("Registry.Register() error = nil, want missing code error")
+	}
+
+	if got, ok := r.Lookup("TS-0001"); !ok || got != notFound {
+		t.Errorf("Registry.Lookup() = %v, %v, want %v", got, ok, notFound)
+	}
+	if _, ok := r.Lookup("TS-0002"); ok {
+		t.Errorf("Registry.Lookup() found an unregistered code")
+	}
+}
+
+func TestRegistry_ExportJSON(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister(InvalidParam, UnknownError)
+
+	byt, err := r.ExportJSON()
+	if err != nil {
+		t.Fatalf("Registry.ExportJSON() error = %v", err)
+	}
+	var got []CatalogEntry
+	if err := json.Unmarshal(byt, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ErrorCode != "BB-0001" || got[1].ErrorCode != "BB-0006" {
+		t.Fatalf("Registry.ExportJSON() = %s", byt)
+	}
+	if got[1].HttpStatus != http.StatusBadRequest || got[1].GrpcStatus != "InvalidArgument" || got[1].LocalizedMessage.Indonesia != "%v tidak sesuai" {
+		t.Errorf("Registry.ExportJSON() entry = %+v", got[1])
+	}
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	if got, ok := Lookup("GW-9999"); !ok || got != UnknownErrorGateway {
+		t.Errorf("Lookup() = %v, %v, want general errors registered", got, ok)
+	}
+}
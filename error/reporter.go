@@ -0,0 +1,178 @@
+package error
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/LukmanulHakim18/core/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	errorsReportedOnce sync.Once
+	errorsReported     *prometheus.CounterVec
+)
+
+// errorsReportedCounter registers the errors counter, labeled by error code and HTTP status,
+// on first use. A service already registering errors_total with the same labels shares it.
+func errorsReportedCounter() *prometheus.CounterVec {
+	errorsReportedOnce.Do(func() {
+		errorsReported = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "errors_total",
+				Help: "Total number of errors built",
+			},
+			[]string{"app_name", "pod_name", "error_code", "status"},
+		)
+		if err := prometheus.Register(errorsReported); err != nil {
+			var registered prometheus.AlreadyRegisteredError
+			if errors.As(err, &registered) {
+				if existing, ok := registered.ExistingCollector.(*prometheus.CounterVec); ok {
+					errorsReported = existing
+				}
+			}
+		}
+	})
+	return errorsReported
+}
+
+// Reporter reports an error built with BuildError, e.g. to APM.
+type Reporter interface {
+	Report(ctx context.Context, err *Error)
+}
+
+// ReporterFunc is an adapter to use a function as Reporter.
+type ReporterFunc func(ctx context.Context, err *Error)
+
+func (f ReporterFunc) Report(ctx context.Context, err *Error) {
+	f(ctx, err)
+}
+
+// ReportPolicy decides which errors are reported. 5xx errors are always reported
+// unless ignored, 4xx errors are sampled. Errors with any other status are reported,
+// since an error mapped to a 2xx or 3xx status is a bug worth seeing.
+type ReportPolicy struct {
+	// ClientErrorSampleRate is the fraction of 4xx errors reported, from 0 (none) to 1 (all)
+	ClientErrorSampleRate float64
+	// IgnoredCodes are error codes never reported
+	IgnoredCodes []string
+}
+
+// DefaultReportPolicy reports every 5xx error and 1% of 4xx errors.
+var DefaultReportPolicy = ReportPolicy{
+	ClientErrorSampleRate: 0.01,
+}
+
+var (
+	reportMu     sync.RWMutex
+	reporters    = []Reporter{NewAPMReporter()}
+	reportPolicy = DefaultReportPolicy
+)
+
+// SetReporters replaces the reporters called by BuildError, APM only by default.
+func SetReporters(r ...Reporter) {
+	reportMu.Lock()
+	defer reportMu.Unlock()
+	reporters = r
+}
+
+// SetReportPolicy sets the policy applied before calling the reporters.
+func SetReportPolicy(policy ReportPolicy) {
+	reportMu.Lock()
+	defer reportMu.Unlock()
+	reportPolicy = policy
+}
+
+// ShouldReport reports whether err is reported under the policy.
+func (p ReportPolicy) ShouldReport(err *Error) bool {
+	for _, code := range p.IgnoredCodes {
+		if code == err.ErrorCode {
+			return false
+		}
+	}
+	switch status := err.httpStatus(); {
+	case status >= 500:
+		return true
+	case status >= 400:
+		return p.ClientErrorSampleRate >= 1 || rand.Float64() < p.ClientErrorSampleRate
+	default:
+		// mis-mapped errors, e.g. a 2xx or 3xx status
+		return true
+	}
+}
+
+func report(ctx context.Context, err *Error) {
+	reportMu.RLock()
+	policy, rs := reportPolicy, reporters
+	reportMu.RUnlock()
+
+	if !policy.ShouldReport(err) {
+		return
+	}
+	for _, r := range rs {
+		r.Report(ctx, err)
+	}
+}
+
+// NewAPMReporter creates a new Reporter sending errors to APM.
+func NewAPMReporter() Reporter {
+	return ReporterFunc(func(ctx context.Context, err *Error) {
+		GetAPMReporter(err).Report(ctx)
+	})
+}
+
+// NewLoggerReporter creates a new Reporter logging 5xx errors as error and others as warning.
+func NewLoggerReporter(l *logger.Logger) Reporter {
+	return ReporterFunc(func(ctx context.Context, err *Error) {
+		news := MakeErrorNews(err)
+		fields := []logger.Field{
+			{Key: "error_code", Value: err.ErrorCode},
+			{Key: "http_status", Value: news.HttpStatus},
+			{Key: "grpc_status", Value: news.GrpcStatus},
+		}
+		if news.Cause != "" {
+			fields = append(fields, logger.Field{Key: "cause", Value: news.Cause})
+		}
+		if err.httpStatus() >= 500 {
+			l.ErrorWithContext(ctx, err.ErrorMessage, fields...)
+		} else {
+			l.WarnWithContext(ctx, err.ErrorMessage, fields...)
+		}
+	})
+}
+
+// NewPrometheusReporter creates a new Reporter counting errors in errors_total,
+// labeled by error code and HTTP status.
+func NewPrometheusReporter() Reporter {
+	appName := os.Getenv("APP_NAME")
+	if appName == "" {
+		appName = "unknown service"
+	}
+
+	podName := os.Getenv("POD_NAME")
+	if podName == "" {
+		podName = "unknown pod"
+	}
+
+	counter := errorsReportedCounter()
+	return ReporterFunc(func(_ context.Context, err *Error) {
+		counter.With(prometheus.Labels{
+			"app_name":   appName,
+			"pod_name":   podName,
+			"error_code": err.ErrorCode,
+			"status":     strconv.Itoa(err.httpStatus()),
+		}).Inc()
+	})
+}
+
+// httpStatus returns the StatusCode, derived from the gRPC code when not set.
+func (e *Error) httpStatus() int {
+	if e.StatusCode == 0 {
+		return GrpcCodeToHTTPStatus(e.GrpcCode())
+	}
+	return e.StatusCode
+}
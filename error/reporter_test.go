@@ -0,0 +1,79 @@
+package error
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestReportPolicy_ShouldReport(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy ReportPolicy
+		err    *Error
+		want   bool
+	}{
+		{name: "server error", policy: ReportPolicy{}, err: UnknownError, want: true},
+		{name: "status derived from gRPC code", policy: ReportPolicy{}, err: &Error{ErrorCode: "X"}, want: true},
+		{name: "client error not sampled", policy: ReportPolicy{}, err: MissingRequiredParam, want: false},
+		{name: "client error always sampled", policy: ReportPolicy{ClientErrorSampleRate: 1}, err: MissingRequiredParam, want: true},
+		{name: "ignored server error", policy: ReportPolicy{IgnoredCodes: []string{"BB-0004"}}, err: ErrorDatabase, want: false},
+		{name: "mis-mapped status", policy: ReportPolicy{}, err: &Error{ErrorCode: "X", StatusCode: http.StatusFound}, want: true},
+		{name: "default policy reports server errors", policy: DefaultReportPolicy, err: UnknownError, want: true},
+		{name: "ignored client error", policy: ReportPolicy{ClientErrorSampleRate: 1, IgnoredCodes: []string{"BB-0005"}}, err: MissingRequiredParam, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.ShouldReport(tt.err); got != tt.want {
+				t.Errorf("ReportPolicy.ShouldReport() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildError_reporters(t *testing.T) {
+	var reported []string
+	SetReporters(NewPrometheusReporter(), ReporterFunc(func(_ context.Context, err *Error) {
+		reported = append(reported, err.ErrorCode)
+	}))
+	SetReportPolicy(ReportPolicy{IgnoredCodes: []string{"BB-0004"}})
+	defer func() {
+		SetReporters(NewAPMReporter())
+		SetReportPolicy(DefaultReportPolicy)
+	}()
+
+	ctx := context.Background()
+	UnknownError.BuildError(ctx)
+	MissingRequiredParam.WithParameter("email").BuildError(ctx)
+	ErrorDatabase.BuildError(ctx)
+	NewErrorWithStatus(http.StatusBadGateway, "GW-0502", "Bad gateway", "Bad gateway", "Bad gateway").BuildError(ctx)
+
+	if len(reported) != 2 || reported[0] != "BB-0001" || reported[1] != "GW-0502" {
+		t.Errorf("reported = %v, want [BB-0001 GW-0502]", reported)
+	}
+	if got := testutil.ToFloat64(errorsReported.WithLabelValues("unknown service", "unknown pod", "GW-0502", "502")); got != 1 {
+		t.Errorf("errors_total = %v, want 1", got)
+	}
+}
+
+func TestNewPrometheusReporter_alreadyRegistered(t *testing.T) {
+	prometheus.Unregister(errorsReportedCounter())
+	errorsReportedOnce = sync.Once{}
+	existing := prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "errors_total", Help: "Total number of errors built"},
+		[]string{"app_name", "pod_name", "error_code", "status"},
+	)
+	if err := prometheus.Register(existing); err != nil {
+		t.Fatal(err)
+	}
+	defer prometheus.Unregister(existing)
+
+	NewPrometheusReporter().Report(context.Background(), UnknownError)
+	if got := testutil.ToFloat64(existing.WithLabelValues("unknown service", "unknown pod", "BB-0001", "500")); got != 1 {
+		t.Errorf("errors_total of the registered counter = %v, want 1", got)
+	}
+}
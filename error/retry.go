@@ -0,0 +1,79 @@
+package error
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	errDetails "google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// WithRetryAfter returns a copy of the error marked retryable after d.
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	err := e.clone()
+	err.Retryable = true
+	err.RetryAfter = d
+	if err.stack == nil {
+		err.stack = callers()
+	}
+	return err
+}
+
+// WithRetryable returns a copy of the error marked retryable or not.
+func (e *Error) WithRetryable(retryable bool) *Error {
+	err := e.clone()
+	err.Retryable = retryable
+	if !retryable {
+		err.RetryAfter = 0
+	}
+	if err.stack == nil {
+		err.stack = callers()
+	}
+	return err
+}
+
+// IsRetryable reports whether the server marked err safe to retry, and how long to wait before.
+// err may be an *Error or a gRPC status error carrying RetryInfo.
+func IsRetryable(err error) (bool, time.Duration) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Retryable, e.RetryAfter
+	}
+	if info := RetryInfoFromError(err); info != nil {
+		return true, info.GetRetryDelay().AsDuration()
+	}
+	return false, 0
+}
+
+// RetryInfoFromError returns the RetryInfo detail of a gRPC status error, nil when there is none.
+func RetryInfoFromError(err error) *errDetails.RetryInfo {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errDetails.RetryInfo); ok {
+			return info
+		}
+	}
+	return nil
+}
+
+func (e *Error) retryInfo() *errDetails.RetryInfo {
+	if !e.Retryable {
+		return nil
+	}
+	return &errDetails.RetryInfo{RetryDelay: durationpb.New(e.RetryAfter)}
+}
+
+// retryAfterHeader returns the Retry-After header value in whole seconds, rounded up.
+func (e *Error) retryAfterHeader() string {
+	if !e.Retryable || e.RetryAfter <= 0 {
+		return ""
+	}
+	return strconv.Itoa(int(math.Ceil(e.RetryAfter.Seconds())))
+}
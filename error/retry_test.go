@@ -0,0 +1,48 @@
+package error
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestError_RetryHints(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            *Error
+		wantRetryable  bool
+		wantRetryAfter time.Duration
+		wantHeader     string
+	}{
+		{name: "not retryable", err: ErrorDatabase},
+		{name: "retryable without delay", err: ErrorDatabase.WithRetryable(true), wantRetryable: true},
+		{name: "retry after", err: ErrorDatabase.WithRetryAfter(1500 * time.Millisecond), wantRetryable: true, wantRetryAfter: 1500 * time.Millisecond, wantHeader: "2"},
+		{name: "retryable reset", err: ErrorDatabase.WithRetryAfter(time.Second).WithRetryable(false)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grpcErr := tt.err.BuildError(context.Background())
+			if retryable, after := IsRetryable(grpcErr); retryable != tt.wantRetryable || after != tt.wantRetryAfter {
+				t.Errorf("IsRetryable(gRPC error) = %v, %v, want %v, %v", retryable, after, tt.wantRetryable, tt.wantRetryAfter)
+			}
+			if retryable, after := IsRetryable(errors.Join(errors.New("call"), tt.err)); retryable != tt.wantRetryable || after != tt.wantRetryAfter {
+				t.Errorf("IsRetryable(*Error) = %v, %v, want %v, %v", retryable, after, tt.wantRetryable, tt.wantRetryAfter)
+			}
+			if got := FromGRPCError(grpcErr); got.Retryable != tt.wantRetryable || got.RetryAfter != tt.wantRetryAfter {
+				t.Errorf("FromGRPCError() = %v, %v, want %v, %v", got.Retryable, got.RetryAfter, tt.wantRetryable, tt.wantRetryAfter)
+			}
+
+			w := httptest.NewRecorder()
+			WriteHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil), grpcErr)
+			if got := w.Header().Get("Retry-After"); got != tt.wantHeader {
+				t.Errorf("WriteHTTP() Retry-After = %q, want %q", got, tt.wantHeader)
+			}
+		})
+	}
+	if ErrorDatabase.Retryable {
+		t.Errorf("WithRetryAfter() modified the shared definition")
+	}
+}
@@ -0,0 +1,86 @@
+package error
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+// StatusClientClosedRequest is the non-standard status used when the client cancels the request.
+const StatusClientClosedRequest = 499
+
+var httpToGrpcCode = map[int]codes.Code{
+	http.StatusOK:                           codes.OK,
+	http.StatusBadRequest:                   codes.InvalidArgument,
+	http.StatusUnauthorized:                 codes.Unauthenticated,
+	http.StatusForbidden:                    codes.PermissionDenied,
+	http.StatusNotFound:                     codes.NotFound,
+	http.StatusMethodNotAllowed:             codes.Unimplemented,
+	http.StatusRequestTimeout:               codes.DeadlineExceeded,
+	http.StatusConflict:                     codes.AlreadyExists,
+	http.StatusPreconditionFailed:           codes.FailedPrecondition,
+	http.StatusRequestedRangeNotSatisfiable: codes.OutOfRange,
+	http.StatusUnprocessableEntity:          codes.InvalidArgument,
+	http.StatusTooManyRequests:              codes.ResourceExhausted,
+	StatusClientClosedRequest:               codes.Canceled,
+	http.StatusInternalServerError:          codes.Internal,
+	http.StatusNotImplemented:               codes.Unimplemented,
+	http.StatusBadGateway:                   codes.Unavailable,
+	http.StatusServiceUnavailable:           codes.Unavailable,
+	http.StatusGatewayTimeout:               codes.DeadlineExceeded,
+}
+
+// grpcToHTTPStatus follows grpc-gateway's HTTPStatusFromCode.
+var grpcToHTTPStatus = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           StatusClientClosedRequest,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// HTTPStatusToGrpcCode returns the gRPC code of an HTTP status. Statuses without
+// their own code map by class: 2xx to OK, 4xx to InvalidArgument and 5xx to Internal.
+func HTTPStatusToGrpcCode(status int) codes.Code {
+	if code, ok := httpToGrpcCode[status]; ok {
+		return code
+	}
+	switch {
+	case status >= 200 && status < 300:
+		return codes.OK
+	case status >= 400 && status < 500:
+		return codes.InvalidArgument
+	case status >= 500 && status < 600:
+		return codes.Internal
+	default:
+		return codes.Unknown
+	}
+}
+
+// GrpcCodeToHTTPStatus returns the HTTP status of a gRPC code, 500 for unknown codes.
+func GrpcCodeToHTTPStatus(code codes.Code) int {
+	if status, ok := grpcToHTTPStatus[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// StatusText is http.StatusText with the non-standard statuses of the mapping.
+func StatusText(status int) string {
+	if status == StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(status)
+}
@@ -0,0 +1,107 @@
+package error
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHTTPStatusToGrpcCode(t *testing.T) {
+	tests := []struct {
+		status int
+		want   codes.Code
+	}{
+		{status: http.StatusOK, want: codes.OK},
+		{status: http.StatusCreated, want: codes.OK},
+		{status: http.StatusBadRequest, want: codes.InvalidArgument},
+		{status: http.StatusUnauthorized, want: codes.Unauthenticated},
+		{status: http.StatusForbidden, want: codes.PermissionDenied},
+		{status: http.StatusNotFound, want: codes.NotFound},
+		{status: http.StatusMethodNotAllowed, want: codes.Unimplemented},
+		{status: http.StatusRequestTimeout, want: codes.DeadlineExceeded},
+		{status: http.StatusConflict, want: codes.AlreadyExists},
+		{status: http.StatusGone, want: codes.InvalidArgument},
+		{status: http.StatusPreconditionFailed, want: codes.FailedPrecondition},
+		{status: http.StatusRequestedRangeNotSatisfiable, want: codes.OutOfRange},
+		{status: http.StatusUnprocessableEntity, want: codes.InvalidArgument},
+		{status: http.StatusTooManyRequests, want: codes.ResourceExhausted},
+		{status: StatusClientClosedRequest, want: codes.Canceled},
+		{status: http.StatusInternalServerError, want: codes.Internal},
+		{status: http.StatusNotImplemented, want: codes.Unimplemented},
+		{status: http.StatusBadGateway, want: codes.Unavailable},
+		{status: http.StatusServiceUnavailable, want: codes.Unavailable},
+		{status: http.StatusGatewayTimeout, want: codes.DeadlineExceeded},
+		{status: http.StatusHTTPVersionNotSupported, want: codes.Internal},
+		{status: 0, want: codes.Unknown},
+		{status: http.StatusFound, want: codes.Unknown},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			if got := HTTPStatusToGrpcCode(tt.status); got != tt.want {
+				t.Errorf("HTTPStatusToGrpcCode(%d) = %v, want %v", tt.status, got, tt.want)
+			}
+			want := tt.want
+			if want == codes.OK {
+				want = codes.Unknown
+			}
+			if got := (Error{StatusCode: tt.status}).GrpcCode(); got != want {
+				t.Errorf("Error.GrpcCode() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestGrpcCodeToHTTPStatus(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		want int
+	}{
+		{code: codes.OK, want: http.StatusOK},
+		{code: codes.Canceled, want: StatusClientClosedRequest},
+		{code: codes.Unknown, want: http.StatusInternalServerError},
+		{code: codes.InvalidArgument, want: http.StatusBadRequest},
+		{code: codes.DeadlineExceeded, want: http.StatusGatewayTimeout},
+		{code: codes.NotFound, want: http.StatusNotFound},
+		{code: codes.AlreadyExists, want: http.StatusConflict},
+		{code: codes.PermissionDenied, want: http.StatusForbidden},
+		{code: codes.ResourceExhausted, want: http.StatusTooManyRequests},
+		{code: codes.FailedPrecondition, want: http.StatusBadRequest},
+		{code: codes.Aborted, want: http.StatusConflict},
+		{code: codes.OutOfRange, want: http.StatusBadRequest},
+		{code: codes.Unimplemented, want: http.StatusNotImplemented},
+		{code: codes.Internal, want: http.StatusInternalServerError},
+		{code: codes.Unavailable, want: http.StatusServiceUnavailable},
+		{code: codes.DataLoss, want: http.StatusInternalServerError},
+		{code: codes.Unauthenticated, want: http.StatusUnauthorized},
+		{code: codes.Code(100), want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			if got := GrpcCodeToHTTPStatus(tt.code); got != tt.want {
+				t.Errorf("GrpcCodeToHTTPStatus(%v) = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMakeErrorNews(t *testing.T) {
+	news := MakeErrorNews(&Error{StatusCode: StatusClientClosedRequest, ErrorMessage: "canceled"})
+	if news.HttpStatus != "[499]-Client Closed Request" || news.GrpcStatus != "[1]-Canceled" {
+		t.Errorf("MakeErrorNews() = %+v", news)
+	}
+}
+
+func TestError_BuildError_nonErrorStatus(t *testing.T) {
+	for _, statusCode := range []int{http.StatusOK, http.StatusNoContent} {
+		err := NewErrorWithStatus(statusCode, "TS-0001", "misconfigured", "misconfigured", "salah konfigurasi").BuildError(context.Background())
+		if err == nil {
+			t.Fatalf("BuildError() with status %d = nil, want an error", statusCode)
+		}
+		if got := status.Code(err); got != codes.Unknown {
+			t.Errorf("BuildError() with status %d code = %v, want %v", statusCode, got, codes.Unknown)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package error
+
+// FieldViolation is the failure of one field of a request.
+type FieldViolation struct {
+	Field            string  `json:"field"`
+	ErrorCode        string  `json:"error_code"`
+	LocalizedMessage Message `json:"localized_message"`
+}
+
+// ValidationError collects the field violations of a request into one Error.
+type ValidationError struct {
+	base       *Error
+	violations []FieldViolation
+}
+
+// NewValidationError creates a new ValidationError building on base,
+// ValidationFailed when base is nil.
+func NewValidationError(base *Error) *ValidationError {
+	if base == nil {
+		base = ValidationFailed
+	}
+	return &ValidationError{base: base}
+}
+
+// Add adds a violation of field with the code and localized message of err,
+// e.g. Add("email", MissingRequiredParam.WithParameter("email")).
+func (v *ValidationError) Add(field string, err *Error) *ValidationError {
+	return v.AddViolation(FieldViolation{
+		Field:            field,
+		ErrorCode:        err.ErrorCode,
+		LocalizedMessage: err.LocalizedMessage.clone(),
+	})
+}
+
+// AddViolation adds a violation, violations are kept in the order they are added.
+func (v *ValidationError) AddViolation(violation FieldViolation) *ValidationError {
+	v.violations = append(v.violations, violation)
+	return v
+}
+
+func (v *ValidationError) HasViolations() bool {
+	return len(v.violations) > 0
+}
+
+// Build returns the Error holding every violation, or nil when there is none.
+// The ErrorField is set to the first violated field for clients reading only one field.
+func (v *ValidationError) Build() *Error {
+	if !v.HasViolations() {
+		return nil
+	}
+	err := v.base.clone()
+	err.ErrorField = v.violations[0].Field
+	err.FieldViolations = append([]FieldViolation(nil), v.violations...)
+	if err.stack == nil {
+		err.stack = callers()
+	}
+	return err
+}
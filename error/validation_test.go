@@ -0,0 +1,80 @@
+package error
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	errDetails "google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func TestValidationError(t *testing.T) {
+	v := NewValidationError(nil)
+	if v.HasViolations() || v.Build() != nil {
+		t.Fatalf("ValidationError.Build() without violations = %v, want nil", v.Build())
+	}
+
+	err := v.Add("email", MissingRequiredParam.WithParameter("email")).
+		Add("phone", InvalidParam.WithParameter("phone")).
+		AddViolation(FieldViolation{Field: "age", ErrorCode: "BK-0010", LocalizedMessage: Message{English: "too young", Indonesia: "terlalu muda"}}).
+		Build()
+
+	if err.ErrorCode != ValidationFailed.ErrorCode || err.ErrorField != "email" {
+		t.Errorf("ValidationError.Build() = %+v", err)
+	}
+
+	byt, _ := json.Marshal(err)
+	var body struct {
+		FieldViolations []FieldViolation `json:"field_violations"`
+	}
+	json.Unmarshal(byt, &body)
+	fields := []string{}
+	for _, fv := range body.FieldViolations {
+		fields = append(fields, fv.Field+"="+fv.ErrorCode)
+	}
+	if want := []string{"email=BB-0005", "phone=BB-0006", "age=BK-0010"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("json field_violations = %v, want %v", fields, want)
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{"accept-language": {"ID"}})
+	st, _ := status.FromError(err.BuildError(ctx))
+	var violations []string
+	for _, d := range st.Details() {
+		if br, ok := d.(*errDetails.BadRequest); ok {
+			for _, fv := range br.FieldViolations {
+				violations = append(violations, fv.Field+": "+fv.Description)
+			}
+		}
+	}
+	if want := []string{"email: email dibutuhkan", "phone: phone tidak sesuai", "age: terlalu muda"}; !reflect.DeepEqual(violations, want) {
+		t.Errorf("BuildError() field violations = %v, want %v", violations, want)
+	}
+
+	if got := FromGRPCError(st.Err()); !reflect.DeepEqual(got.FieldViolations, err.FieldViolations) {
+		t.Errorf("FromGRPCError() field violations = %+v, want %+v", got.FieldViolations, err.FieldViolations)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/users", nil)
+	r.Header.Set("Accept", ContentTypeProblemJSON)
+	w := httptest.NewRecorder()
+	WriteHTTP(w, r, err)
+	var problem ProblemDetails
+	json.Unmarshal(w.Body.Bytes(), &problem)
+	want := []ProblemParam{{Name: "email", Reason: "email is required"}, {Name: "phone", Reason: "phone is invalid"}, {Name: "age", Reason: "too young"}}
+	if !reflect.DeepEqual(problem.InvalidParams, want) {
+		t.Errorf("WriteHTTP() invalid_params = %+v, want %+v", problem.InvalidParams, want)
+	}
+
+	if got := err.ProblemDetails("custom detail", http.StatusBadRequest); got.Detail != "custom detail" || !reflect.DeepEqual(got.InvalidParams, want) {
+		t.Errorf("ProblemDetails() = %q %+v, want %q %+v", got.Detail, got.InvalidParams, "custom detail", want)
+	}
+	if got := err.ProblemDetailsLocalized("id", http.StatusBadRequest); got.InvalidParams[0].Reason != "email dibutuhkan" {
+		t.Errorf("ProblemDetailsLocalized() invalid_params = %+v, want Indonesian reasons", got.InvalidParams)
+	}
+}
@@ -0,0 +1,155 @@
+package error
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/LukmanulHakim18/core/logger"
+)
+
+const maxStackDepth = 32
+
+var (
+	stackCapture atomic.Bool
+
+	causeLoggerMu sync.RWMutex
+	causeLogger   *logger.Logger
+)
+
+// SetStackCapture enables capturing the call stack when an error is created or wrapped.
+// It is disabled by default as capturing has a cost on every error.
+func SetStackCapture(enabled bool) {
+	stackCapture.Store(enabled)
+}
+
+// SetLogger sets the logger used by BuildError to log the cause chain of wrapped errors.
+func SetLogger(l *logger.Logger) {
+	causeLoggerMu.Lock()
+	defer causeLoggerMu.Unlock()
+	causeLogger = l
+}
+
+func getLogger() *logger.Logger {
+	causeLoggerMu.RLock()
+	defer causeLoggerMu.RUnlock()
+	return causeLogger
+}
+
+// Wrap returns a copy of the error with cause attached. The cause is kept for
+// logging and APM only and is never part of Error() or the JSON response.
+func (e *Error) Wrap(cause error) *Error {
+	err := e.clone()
+	err.cause = cause
+	err.stack = callers()
+	return err
+}
+
+// Unwrap returns the cause attached with Wrap.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether target is an *Error with the same ErrorCode, so errors.Is matches
+// derived errors against their definition, e.g. errors.Is(err, UnknownError).
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok || t == nil {
+		return false
+	}
+	return e.ErrorCode == t.ErrorCode
+}
+
+// Cause returns the innermost error of the cause chain, or nil when there is no cause.
+func (e *Error) Cause() error {
+	var cause error
+	for err := e.cause; err != nil; err = errors.Unwrap(err) {
+		cause = err
+	}
+	return cause
+}
+
+// StackTrace returns the stack captured at creation, one "function file:line" per frame.
+// It is empty unless SetStackCapture(true) was called before the error was created.
+// An error derived from a definition, e.g. with WithParameter, keeps the stack of its
+// source when it has one, e.g. a wrapped error, else the stack where it was derived.
+func (e *Error) StackTrace() []string {
+	if len(e.stack) == 0 {
+		return nil
+	}
+	res := make([]string, 0, len(e.stack))
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		res = append(res, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+		if !more {
+			break
+		}
+	}
+	return res
+}
+
+// causeChain returns the messages of the cause chain joined by ": ".
+func (e *Error) causeChain() string {
+	messages := []string{}
+	for err := e.cause; err != nil; err = errors.Unwrap(err) {
+		if inner, ok := err.(*Error); ok {
+			messages = append(messages, fmt.Sprintf("[%s] %s", inner.ErrorCode, inner.ErrorMessage))
+			continue
+		}
+		messages = append(messages, err.Error())
+		if errors.Unwrap(err) != nil && strings.Contains(err.Error(), errors.Unwrap(err).Error()) {
+			// the message already contains the rest of the chain, e.g. fmt.Errorf("...: %w", err)
+			break
+		}
+	}
+	return strings.Join(messages, ": ")
+}
+
+func (e *Error) logCause(ctx context.Context) {
+	l := getLogger()
+	if l == nil || e.cause == nil {
+		return
+	}
+	fields := []logger.Field{
+		{Key: "error_code", Value: e.ErrorCode},
+		{Key: "cause", Value: e.causeChain()},
+	}
+	if stack := e.StackTrace(); stack != nil {
+		fields = append(fields, logger.Field{Key: "stack", Value: stack})
+	}
+	l.ErrorWithContext(ctx, e.ErrorMessage, fields...)
+}
+
+// clone returns a copy of the error that shares nothing mutable with e.
+func (e *Error) clone() *Error {
+	err := *e
+	err.LocalizedMessage = e.LocalizedMessage.clone()
+	err.Data = append([]Data(nil), e.Data...)
+	err.ErrorData = append([]Data(nil), e.ErrorData...)
+	err.FieldViolations = append([]FieldViolation(nil), e.FieldViolations...)
+	if len(err.Data) == 0 {
+		err.Data = nil
+	}
+	if len(err.ErrorData) == 0 {
+		err.ErrorData = nil
+	}
+	if len(err.FieldViolations) == 0 {
+		err.FieldViolations = nil
+	}
+	return &err
+}
+
+// callers returns the stack of the caller of the function calling it, when capture is enabled.
+func callers() []uintptr {
+	if !stackCapture.Load() {
+		return nil
+	}
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(3, pcs)
+	return pcs[:n]
+}
@@ -0,0 +1,98 @@
+package error
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestError_Wrap(t *testing.T) {
+	cause := fmt.Errorf("select booking: %w", sql.ErrConnDone)
+	err := ErrorDatabase.WithData([]Data{{Key: "booking_id", Value: "1"}}).WithField("booking_id").Wrap(cause)
+
+	if !errors.Is(err, sql.ErrConnDone) {
+		t.Errorf("errors.Is(err, sql.ErrConnDone) = false, want true")
+	}
+	if !errors.Is(fmt.Errorf("repository: %w", err), ErrorDatabase) {
+		t.Errorf("errors.Is(err, ErrorDatabase) = false, want true")
+	}
+	if errors.Is(err, UnknownError) {
+		t.Errorf("errors.Is(err, UnknownError) = true, want false")
+	}
+	var target *Error
+	if !errors.As(fmt.Errorf("repository: %w", err), &target) || target.ErrorCode != ErrorDatabase.ErrorCode {
+		t.Errorf("errors.As() = %v, want %s", target, ErrorDatabase.ErrorCode)
+	}
+	if err.Cause() != sql.ErrConnDone {
+		t.Errorf("Error.Cause() = %v, want %v", err.Cause(), sql.ErrConnDone)
+	}
+	if got := err.causeChain(); got != cause.Error() {
+		t.Errorf("Error.causeChain() = %v, want %v", got, cause.Error())
+	}
+	if ErrorDatabase.Unwrap() != nil {
+		t.Errorf("Wrap() modified the shared definition")
+	}
+
+	// the cause never reaches the client
+	byt, _ := json.Marshal(err)
+	if strings.Contains(string(byt), "select booking") || strings.Contains(err.Error(), "select booking") {
+		t.Errorf("client facing error leaks the cause: %s, %s", byt, err.Error())
+	}
+	if err.ErrorField != "booking_id" || len(err.Data) != 1 {
+		t.Errorf("Wrap() = %+v, want ErrorField and Data kept", err)
+	}
+	if news := MakeErrorNews(err); !errors.Is(news, sql.ErrConnDone) || news.Cause == "" {
+		t.Errorf("MakeErrorNews() = %+v, want the cause chain", news)
+	}
+	if st := err.BuildError(context.Background()); strings.Contains(st.Error(), "select booking") {
+		t.Errorf("BuildError() = %v, leaks the cause", st)
+	}
+}
+
+func TestError_derivationKeepsFields(t *testing.T) {
+	base := MissingRequiredParam.WithField("email").WithData([]Data{{Key: "k", Value: "v"}})
+	base = base.WithErrorData(base.ErrorCode, map[string]string{"field": "email"}).Wrap(errors.New("empty email"))
+
+	err := base.WithParameter("email")
+	if err.ErrorField != "email" || len(err.Data) != 1 || len(err.ErrorData) != 1 || err.Unwrap() == nil {
+		t.Errorf("WithParameter() = %+v, want ErrorField, Data, ErrorData and cause kept", err)
+	}
+	if err.ErrorMessage != "email is required" {
+		t.Errorf("WithParameter() ErrorMessage = %v", err.ErrorMessage)
+	}
+
+	err.Data[0].Value = "changed"
+	if base.Data[0].Value != "v" {
+		t.Errorf("WithParameter() shares Data with the source error")
+	}
+}
+
+func TestError_StackTrace(t *testing.T) {
+	if UnknownError.Wrap(errors.New("cause")).StackTrace() != nil {
+		t.Errorf("StackTrace() captured while disabled")
+	}
+
+	SetStackCapture(true)
+	defer SetStackCapture(false)
+	stack := UnknownError.Wrap(errors.New("cause")).StackTrace()
+	if len(stack) == 0 || !strings.Contains(stack[0], "TestError_StackTrace") {
+		t.Errorf("StackTrace() = %v, want the caller of Wrap first", stack)
+	}
+
+	stack = MissingRequiredParam.WithParameter("email").StackTrace()
+	if len(stack) == 0 || !strings.Contains(stack[0], "TestError_StackTrace") {
+		t.Errorf("WithParameter() StackTrace() = %v, want the caller of WithParameter first", stack)
+	}
+	if MissingRequiredParam.StackTrace() != nil {
+		t.Errorf("WithParameter() captured a stack on the definition")
+	}
+
+	wrapped := UnknownError.Wrap(errors.New("cause"))
+	if got := wrapped.WithRetryable(true).StackTrace(); len(got) != len(wrapped.StackTrace()) || got[0] != wrapped.StackTrace()[0] {
+		t.Errorf("WithRetryable() StackTrace() = %v, want the stack of the wrapped error", got)
+	}
+}
@@ -0,0 +1,166 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+	"github.com/go-kit/kit/sd/lb"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+
+	ulog "github.com/LukmanulHakim18/core/log"
+	"github.com/LukmanulHakim18/core/microservice"
+)
+
+// LoadBalancedEndpoint returns an endpoint balancing calls round robin over the instances
+// of serviceName found on the discovery nodes. Failed calls are retried up to option.Retry
+// times within option.RetryTimeout, and option.Timeout is passed to makeEndpoint.
+// Connections to removed instances are closed as they disappear, and the returned
+// closer stops the discovery and closes every remaining connection. With option.HealthCheck,
+// instances whose health check reports NOT_SERVING are left out of the rotation while
+// they stay registered.
+func LoadBalancedEndpoint(nodes []string, serviceName string, makeEndpoint func(*grpc.ClientConn, time.Duration, stdopentracing.Tracer, log.Logger) endpoint.Endpoint, creds credentials.TransportCredentials, option ClientOption, tracer stdopentracing.Tracer, logger log.Logger) (endpoint.Endpoint, io.Closer, error) {
+	instancer, err := microservice.ServiceDiscovery(nodes, serviceName, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	ep, closer := loadBalancedEndpoint(instancer, makeEndpoint, creds, option, tracer, logger)
+	return ep, closer, nil
+}
+
+func loadBalancedEndpoint(instancer sd.Instancer, makeEndpoint func(*grpc.ClientConn, time.Duration, stdopentracing.Tracer, log.Logger) endpoint.Endpoint, creds credentials.TransportCredentials, option ClientOption, tracer stdopentracing.Tracer, logger log.Logger, opts ...grpc.DialOption) (endpoint.Endpoint, io.Closer) {
+	conns := &connSet{}
+	factory := func(instance string) (endpoint.Endpoint, io.Closer, error) {
+		if instance == "" {
+			return nil, nil, errors.New("Empty instance")
+		}
+
+		conn, err := grpcConnectionWithOption(instance, creds, option, opts...)
+		if err != nil {
+			logger.Log("host", instance, ulog.LogError, err.Error())
+			return nil, nil, err
+		}
+
+		ep := makeEndpoint(conn, option.Timeout, tracer, logger)
+		return ep, conns.add(conn, ep), nil
+	}
+
+	// the endpointer keeps conns in sync with the discovered instances
+	endpointer := sd.NewEndpointer(instancer, factory, logger)
+	balancer := lb.NewRoundRobin(conns)
+
+	retryTimeout := option.RetryTimeout
+	if retryTimeout <= 0 {
+		retryTimeout = option.Timeout
+	}
+
+	var ep endpoint.Endpoint
+	if retryTimeout > 0 {
+		ep = lb.Retry(option.Retry, retryTimeout, balancer)
+	} else {
+		ep = func(ctx context.Context, request interface{}) (interface{}, error) {
+			next, err := balancer.Endpoint()
+			if err != nil {
+				return nil, err
+			}
+			return next(ctx, request)
+		}
+	}
+
+	return ep, &loadBalancedCloser{instancer: instancer, endpointer: endpointer, conns: conns}
+}
+
+// connSet tracks the connections opened for discovered instances.
+type connSet struct {
+	mu        sync.Mutex
+	instances []instanceConn
+}
+
+type instanceConn struct {
+	conn     *grpc.ClientConn
+	endpoint endpoint.Endpoint
+}
+
+func (s *connSet) add(conn *grpc.ClientConn, ep endpoint.Endpoint) io.Closer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.instances = append(s.instances, instanceConn{conn: conn, endpoint: ep})
+	return connCloser{set: s, conn: conn}
+}
+
+func (s *connSet) close(conn *grpc.ClientConn) error {
+	s.mu.Lock()
+	for i, instance := range s.instances {
+		if instance.conn == conn {
+			s.instances = append(s.instances[:i:i], s.instances[i+1:]...)
+			break
+		}
+	}
+	s.mu.Unlock()
+	return conn.Close()
+}
+
+func (s *connSet) closeAll() {
+	s.mu.Lock()
+	instances := s.instances
+	s.instances = nil
+	s.mu.Unlock()
+
+	for _, instance := range instances {
+		instance.conn.Close()
+	}
+}
+
+// Endpoints implements sd.Endpointer. Instances in TRANSIENT_FAILURE, e.g. reporting
+// NOT_SERVING with ClientOption.HealthCheck, are skipped unless every instance is failing.
+func (s *connSet) Endpoints() ([]endpoint.Endpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	healthy := make([]endpoint.Endpoint, 0, len(s.instances))
+	for _, instance := range s.instances {
+		if instance.conn.GetState() != connectivity.TransientFailure {
+			healthy = append(healthy, instance.endpoint)
+		}
+	}
+	if len(healthy) > 0 {
+		return healthy, nil
+	}
+
+	all := make([]endpoint.Endpoint, 0, len(s.instances))
+	for _, instance := range s.instances {
+		all = append(all, instance.endpoint)
+	}
+	return all, nil
+}
+
+type connCloser struct {
+	set  *connSet
+	conn *grpc.ClientConn
+}
+
+// Close is called by the endpointer when the instance is removed from discovery.
+func (c connCloser) Close() error {
+	return c.set.close(c.conn)
+}
+
+type loadBalancedCloser struct {
+	instancer  sd.Instancer
+	endpointer *sd.DefaultEndpointer
+	conns      *connSet
+}
+
+func (c *loadBalancedCloser) Close() error {
+	c.endpointer.Close()
+	c.instancer.Stop()
+	c.conns.closeAll()
+	return nil
+}
@@ -0,0 +1,279 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testInstancer is an sd.Instancer whose instances are set by the test.
+type testInstancer struct {
+	mu        sync.Mutex
+	instances []string
+	chs       map[chan<- sd.Event]bool
+}
+
+func (i *testInstancer) Register(ch chan<- sd.Event) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.chs == nil {
+		i.chs = map[chan<- sd.Event]bool{}
+	}
+	i.chs[ch] = true
+	ch <- sd.Event{Instances: i.instances}
+}
+
+func (i *testInstancer) Deregister(ch chan<- sd.Event) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.chs, ch)
+}
+
+func (i *testInstancer) Stop() {}
+
+func (i *testInstancer) set(instances ...string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.instances = instances
+	for ch := range i.chs {
+		ch <- sd.Event{Instances: instances}
+	}
+}
+
+// testCluster serves the health service on a bufconn listener per instance.
+type testCluster struct {
+	listeners map[string]*bufconn.Listener
+	servers   map[string]*grpc.Server
+	health    map[string]*health.Server
+
+	mu       sync.Mutex
+	conns    map[string]*grpc.ClientConn
+	timeouts []time.Duration
+}
+
+func newTestCluster(t *testing.T, instances ...string) *testCluster {
+	c := &testCluster{
+		listeners: map[string]*bufconn.Listener{},
+		servers:   map[string]*grpc.Server{},
+		health:    map[string]*health.Server{},
+		conns:     map[string]*grpc.ClientConn{},
+	}
+	for _, instance := range instances {
+		c.listeners[instance] = bufconn.Listen(bufSize)
+		c.servers[instance] = grpc.NewServer()
+		c.health[instance] = health.NewServer()
+		healthpb.RegisterHealthServer(c.servers[instance], c.health[instance])
+		go c.servers[instance].Serve(c.listeners[instance])
+	}
+	t.Cleanup(func() {
+		for _, instance := range instances {
+			c.stop(instance)
+		}
+	})
+	return c
+}
+
+const bufSize = 1024 * 1024
+
+func (c *testCluster) stop(instance string) {
+	c.servers[instance].Stop()
+	c.listeners[instance].Close()
+}
+
+func (c *testCluster) dialer() grpc.DialOption {
+	return grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		return c.listeners[addr].DialContext(ctx)
+	})
+}
+
+// makeEndpoint returns the instance serving the call, or an error when it is not serving.
+func (c *testCluster) makeEndpoint(conn *grpc.ClientConn, timeout time.Duration, _ stdopentracing.Tracer, _ log.Logger) endpoint.Endpoint {
+	c.mu.Lock()
+	c.conns[conn.Target()] = conn
+	c.timeouts = append(c.timeouts, timeout)
+	c.mu.Unlock()
+
+	client := healthpb.NewHealthClient(conn)
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		res, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			return nil, err
+		}
+		if res.Status != healthpb.HealthCheckResponse_SERVING {
+			return nil, errors.New(conn.Target() + " is not serving")
+		}
+		return conn.Target(), nil
+	}
+}
+
+func (c *testCluster) conn(instance string) *grpc.ClientConn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conns[instance]
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLoadBalancedEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		option   ClientOption
+		failing  string
+		wantCode codes.Code
+		want     []interface{}
+	}{
+		{
+			name:   "round robin across instances",
+			option: ClientOption{Timeout: time.Second},
+			want:   []interface{}{"a", "b", "a", "b"},
+		},
+		{
+			name:    "retry after one failure",
+			option:  ClientOption{Timeout: time.Second, Retry: 2},
+			failing: "a",
+			want:    []interface{}{"b", "b", "b", "b"},
+		},
+		{
+			name:     "max call recv msg size",
+			option:   ClientOption{MaxCallRecvMsgSize: 1},
+			wantCode: codes.ResourceExhausted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newTestCluster(t, "a", "b")
+			if tt.failing != "" {
+				cluster.health[tt.failing].SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+			}
+			instancer := &testInstancer{instances: []string{"a", "b"}}
+			ep, closer := loadBalancedEndpoint(instancer, cluster.makeEndpoint, nil, tt.option, nil, log.NewNopLogger(), cluster.dialer())
+			defer closer.Close()
+			waitFor(t, "both instances", func() bool {
+				return cluster.conn("a") != nil && cluster.conn("b") != nil
+			})
+
+			if tt.wantCode != codes.OK {
+				_, err := ep(context.Background(), nil)
+				if status.Code(err) != tt.wantCode {
+					t.Fatalf("endpoint error = %v, want %v", err, tt.wantCode)
+				}
+				return
+			}
+
+			got := map[interface{}]int{}
+			for range tt.want {
+				res, err := ep(context.Background(), nil)
+				if err != nil {
+					t.Fatalf("endpoint error = %v", err)
+				}
+				got[res]++
+			}
+			want := map[interface{}]int{}
+			for _, res := range tt.want {
+				want[res]++
+			}
+			for res, n := range want {
+				if got[res] != n {
+					t.Errorf("endpoint results = %v, want %v", got, want)
+				}
+			}
+			for _, timeout := range cluster.timeouts {
+				if timeout != tt.option.Timeout {
+					t.Errorf("makeEndpoint timeout = %v, want %v", timeout, tt.option.Timeout)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadBalancedEndpoint_closesConns(t *testing.T) {
+	cluster := newTestCluster(t, "a", "b")
+	instancer := &testInstancer{instances: []string{"a", "b"}}
+	_, closer := loadBalancedEndpoint(instancer, cluster.makeEndpoint, nil, ClientOption{}, nil, log.NewNopLogger(), cluster.dialer())
+	waitFor(t, "both instances", func() bool {
+		return cluster.conn("a") != nil && cluster.conn("b") != nil
+	})
+
+	instancer.set("b")
+	waitFor(t, "the conn of the removed instance to close", func() bool {
+		return cluster.conn("a").GetState() == connectivity.Shutdown
+	})
+	if cluster.conn("b").GetState() == connectivity.Shutdown {
+		t.Fatalf("conn of the remaining instance is closed")
+	}
+
+	closer.Close()
+	if state := cluster.conn("b").GetState(); state != connectivity.Shutdown {
+		t.Errorf("conn state after Close() = %v, want %v", state, connectivity.Shutdown)
+	}
+}
+
+func TestConnSet_Endpoints(t *testing.T) {
+	cluster := newTestCluster(t, "a", "b")
+	instancer := &testInstancer{instances: []string{"a", "b"}}
+	_, closer := loadBalancedEndpoint(instancer, cluster.makeEndpoint, nil, ClientOption{HealthCheck: true}, nil, log.NewNopLogger(), cluster.dialer())
+	defer closer.Close()
+	conns := closer.(*loadBalancedCloser).conns
+
+	count := func() int {
+		endpoints, _ := conns.Endpoints()
+		return len(endpoints)
+	}
+	waitFor(t, "both instances", func() bool { return count() == 2 })
+	for _, instance := range []string{"a", "b"} {
+		cluster.conn(instance).Connect()
+	}
+
+	registered := func() int {
+		conns.mu.Lock()
+		defer conns.mu.Unlock()
+		return len(conns.instances)
+	}
+	cluster.health["a"].SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	waitFor(t, "the not serving instance to leave the endpoints", func() bool { return count() == 1 })
+	endpoints, _ := conns.Endpoints()
+	if res, err := endpoints[0](context.Background(), nil); err != nil || res != "b" {
+		t.Errorf("remaining endpoint = %v, %v, want b", res, err)
+	}
+	if got := registered(); got != 2 {
+		t.Errorf("registered instances = %d, want the not serving instance kept", got)
+	}
+	cluster.health["a"].SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	waitFor(t, "the instance serving again to come back", func() bool { return count() == 2 })
+
+	cluster.stop("a")
+	waitFor(t, "the stopped instance to leave the endpoints", func() bool { return count() == 1 })
+	endpoints, _ = conns.Endpoints()
+	if res, err := endpoints[0](context.Background(), nil); err != nil || res != "b" {
+		t.Errorf("remaining endpoint = %v, %v, want b", res, err)
+	}
+
+	cluster.stop("b")
+	waitFor(t, "every instance back when all are failing", func() bool {
+		return cluster.conn("b").GetState() == connectivity.TransientFailure && count() == 2
+	})
+}
@@ -16,6 +16,12 @@ import (
 	"google.golang.org/grpc"
 )
 
+// healthCheckServiceConfig enables client side health checking on the overall
+// status, so instances reporting NOT_SERVING go to TRANSIENT_FAILURE.
+// Servers without the health service are treated as serving.
+// The health checking function is registered by google.golang.org/grpc/health.
+const healthCheckServiceConfig = `{"loadBalancingConfig":[{"round_robin":{}}],"healthCheckConfig":{"serviceName":""}}`
+
 // ClientOption stores grpc client options
 type ClientOption struct {
 	//Timeout for circuit breaker
@@ -26,34 +32,32 @@ type ClientOption struct {
 	RetryTimeout time.Duration
 	// ...
 	MaxCallRecvMsgSize int
+	// HealthCheck enables client side health checking, instances reporting
+	// NOT_SERVING go to TRANSIENT_FAILURE and leave the balancing rotation
+	HealthCheck bool
 }
 
 func grpcConnection(address string, creds credentials.TransportCredentials) (*grpc.ClientConn, error) {
-	var conn *grpc.ClientConn
-	var err error
-	if creds == nil {
-		conn, err = grpc.Dial(address, grpc.WithInsecure())
-	} else {
-		conn, err = grpc.Dial(address, grpc.WithTransportCredentials(creds))
-	}
-	if err != nil {
-		return nil, err
-	}
-	return conn, nil
+	return grpcConnectionWithOption(address, creds, ClientOption{})
 }
 
 func grpcConnectionWithMaxCallRecvMsgSize(address string, creds credentials.TransportCredentials, maxCallRecvMsgSize int) (*grpc.ClientConn, error) {
-	var conn *grpc.ClientConn
-	var err error
+	return grpcConnectionWithOption(address, creds, ClientOption{MaxCallRecvMsgSize: maxCallRecvMsgSize})
+}
+
+func grpcConnectionWithOption(address string, creds credentials.TransportCredentials, option ClientOption, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
 	if creds == nil {
-		conn, err = grpc.Dial(address, grpc.WithInsecure(), grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxCallRecvMsgSize)))
+		opts = append(opts, grpc.WithInsecure())
 	} else {
-		conn, err = grpc.Dial(address, grpc.WithTransportCredentials(creds), grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxCallRecvMsgSize)))
+		opts = append(opts, grpc.WithTransportCredentials(creds))
+	}
+	if option.HealthCheck {
+		opts = append(opts, grpc.WithDefaultServiceConfig(healthCheckServiceConfig))
 	}
-	if err != nil {
-		return nil, err
+	if option.MaxCallRecvMsgSize > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(option.MaxCallRecvMsgSize)))
 	}
-	return conn, nil
+	return grpc.Dial(address, opts...)
 }
 
 // EndpointFactory returns endpoint factory
@@ -92,3 +96,23 @@ func EndpointFactoryWithMaxCallRecvMsgSize(makeEndpoint func(*grpc.ClientConn, t
 		return endpoint, conn, nil
 	}
 }
+
+// EndpointFactoryWithOption returns endpoint factory dialing with option, e.g. to
+// opt in to client side health checking with option.HealthCheck.
+func EndpointFactoryWithOption(makeEndpoint func(*grpc.ClientConn, time.Duration, stdopentracing.Tracer, log.Logger) endpoint.Endpoint, creds credentials.TransportCredentials, option ClientOption, tracer stdopentracing.Tracer, logger log.Logger) sd.Factory {
+	return func(instance string) (endpoint.Endpoint, io.Closer, error) {
+
+		if instance == "" {
+			return nil, nil, errors.New("Empty instance")
+		}
+
+		conn, err := grpcConnectionWithOption(instance, creds, option)
+		if err != nil {
+			logger.Log("host", instance, ulog.LogError, err.Error())
+			return nil, nil, err
+		}
+		endpoint := makeEndpoint(conn, option.Timeout, tracer, logger)
+
+		return endpoint, conn, nil
+	}
+}
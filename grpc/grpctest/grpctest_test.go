@@ -0,0 +1,138 @@
+package grpctest_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	coreError "github.com/LukmanulHakim18/core/error"
+	coreGrpc "github.com/LukmanulHakim18/core/grpc"
+	"github.com/LukmanulHakim18/core/grpc/grpctest"
+	"github.com/LukmanulHakim18/core/logger"
+	meta "github.com/LukmanulHakim18/core/metadata"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestServer(t *testing.T) {
+	var got meta.Metadata
+	srv := grpctest.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		got = meta.GetMetaDataFromContext(ctx)
+		if got.UserInfo == nil {
+			return nil, coreError.GetUnauthorizedAccess("BB-0401").BuildError(ctx)
+		}
+		return handler(ctx, req)
+	}))
+	healthpb.RegisterHealthServer(srv.Server, health.NewServer())
+	srv.Start()
+	defer srv.Close()
+
+	log, err := logger.NewLogger(logger.LoggerConfig{Level: logger.LevelError})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := srv.Dial(grpctest.ProductionInterceptors(log, "grpctest", nil)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	t.Run("metadata reaches the server", func(t *testing.T) {
+		ctx := grpctest.OutgoingContext(context.Background(),
+			grpctest.WithAcceptLanguage("id"),
+			grpctest.WithAppVersion("6.2.1"),
+			grpctest.WithTraceId("trace-1"),
+			grpctest.WithUserInfo(meta.UserInfo{InternalID: "BB12345"}),
+		)
+		if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+		if !got.DeviceLang.IsId() || got.AppVersion.String() != "6.2.1" || got.TraceId != "trace-1" || got.UserInfo.InternalID != "BB12345" {
+			t.Errorf("GetMetaDataFromContext() = %+v", got)
+		}
+	})
+
+	t.Run("BuildError details reach the client", func(t *testing.T) {
+		ctx := grpctest.OutgoingContext(context.Background(), grpctest.WithAcceptLanguage("id"))
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		st := status.Convert(err)
+		if st.Code() != codes.Unauthenticated || st.Message() != "user-info tidak ditemukan" {
+			t.Fatalf("Check() error = %v", err)
+		}
+		var reason string
+		for _, d := range st.Details() {
+			if info, ok := d.(*errdetails.ErrorInfo); ok {
+				reason = info.Reason
+			}
+		}
+		if reason != "BB-0401" {
+			t.Errorf("ErrorInfo.Reason = %q, want %q", reason, "BB-0401")
+		}
+	})
+}
+
+func TestServer_userInfoRejected(t *testing.T) {
+	keyring := meta.NewKeyring(meta.NewHMACKey("2025", []byte("secret")))
+	meta.SetUserInfoVerification(keyring, meta.UserInfoReject)
+	defer meta.SetUserInfoVerification(nil, meta.UserInfoTrusted)
+
+	metadataInterceptor := coreGrpc.NewMetadataServerInterceptor()
+	srv := grpctest.NewServer(grpc.UnaryInterceptor(metadataInterceptor.UnaryServerInterceptor()))
+	healthpb.RegisterHealthServer(srv.Server, health.NewServer())
+	srv.Start()
+	defer srv.Close()
+
+	conn, err := srv.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	userInfo := meta.UserInfo{InternalID: "BB12345"}
+	byt, _ := json.Marshal(userInfo)
+	signature, err := keyring.Sign(byt, "req-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     []grpctest.MetadataOption
+		wantCode codes.Code
+	}{
+		{
+			name:     "signed user-info",
+			opts:     []grpctest.MetadataOption{grpctest.WithUserInfo(userInfo), grpctest.WithMetadata(meta.MetadataRequestId, "req-1"), grpctest.WithMetadata(meta.MetadataUserInfoSignature, signature)},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "without user-info",
+			opts:     []grpctest.MetadataOption{grpctest.WithMetadata(meta.MetadataRequestId, "req-1")},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "spoofed user-info",
+			opts:     []grpctest.MetadataOption{grpctest.WithUserInfo(meta.UserInfo{InternalID: "BB99999"}), grpctest.WithMetadata(meta.MetadataRequestId, "req-1"), grpctest.WithMetadata(meta.MetadataUserInfoSignature, signature)},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "unsigned user-info",
+			opts:     []grpctest.MetadataOption{grpctest.WithUserInfo(userInfo)},
+			wantCode: codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Check(grpctest.OutgoingContext(context.Background(), tt.opts...), &healthpb.HealthCheckRequest{})
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("Check() error = %v, want %v", err, tt.wantCode)
+			}
+		})
+	}
+}
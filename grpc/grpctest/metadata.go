@@ -0,0 +1,62 @@
+package grpctest
+
+import (
+	"context"
+	"encoding/json"
+
+	meta "github.com/LukmanulHakim18/core/metadata"
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataOption sets a key on the faked metadata.
+type MetadataOption func(md metadata.MD)
+
+// WithMetadata sets an arbitrary metadata key.
+func WithMetadata(key string, values ...string) MetadataOption {
+	return func(md metadata.MD) {
+		md.Set(key, values...)
+	}
+}
+
+// WithAcceptLanguage sets the accept-language metadata, e.g. "id" or "en".
+func WithAcceptLanguage(lang string) MetadataOption {
+	return WithMetadata(meta.MetadataAcceptLang, lang)
+}
+
+// WithAppVersion sets the app-version metadata, e.g. "6.2.1".
+func WithAppVersion(version string) MetadataOption {
+	return WithMetadata(meta.MetadataAppVersion, version)
+}
+
+// WithTraceId sets the trace-id metadata.
+func WithTraceId(traceId string) MetadataOption {
+	return WithMetadata(meta.MetadataTraceId, traceId)
+}
+
+// WithUserInfo sets the user-info metadata as JSON.
+func WithUserInfo(userInfo meta.UserInfo) MetadataOption {
+	return func(md metadata.MD) {
+		byt, _ := json.Marshal(userInfo)
+		md.Set(meta.MetadataUserInfo, string(byt))
+	}
+}
+
+// IncomingContext returns a context carrying the faked metadata as incoming
+// metadata, as seen by a server handler.
+func IncomingContext(ctx context.Context, opts ...MetadataOption) context.Context {
+	return metadata.NewIncomingContext(ctx, newMD(opts))
+}
+
+// OutgoingContext returns a context carrying the faked metadata as outgoing
+// metadata, to be sent by a client through the test server.
+func OutgoingContext(ctx context.Context, opts ...MetadataOption) context.Context {
+	return metadata.NewOutgoingContext(ctx, newMD(opts))
+}
+
+func newMD(opts []MetadataOption) metadata.MD {
+	md := metadata.MD{}
+	for _, opt := range opts {
+		opt(md)
+	}
+	return md
+}
@@ -0,0 +1,129 @@
+package grpc
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthCheck returns an error when a dependency is unhealthy.
+type HealthCheck func(ctx context.Context) error
+
+// DatabaseCheck pings the database.
+func DatabaseCheck(db *sql.DB) HealthCheck {
+	return db.PingContext
+}
+
+// PingCheck adapts a ping without context, e.g. ClientRedis.Ping.
+func PingCheck(ping func() error) HealthCheck {
+	return func(context.Context) error {
+		return ping()
+	}
+}
+
+// HealthChecker serves the standard grpc.health.v1 service. Every check is reported
+// as its own service name, and the overall status ("") is SERVING only when all pass.
+type HealthChecker struct {
+	server   *health.Server
+	interval time.Duration
+	timeout  time.Duration
+	mu       sync.Mutex
+	checks   map[string]HealthCheck
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewHealthChecker creates a new HealthChecker running the checks every interval,
+// each bounded by timeout.
+func NewHealthChecker(interval, timeout time.Duration) *HealthChecker {
+	return &HealthChecker{
+		server:   health.NewServer(),
+		interval: interval,
+		timeout:  timeout,
+		checks:   map[string]HealthCheck{},
+		stop:     make(chan struct{}),
+	}
+}
+
+// AddCheck registers a dependency check under name, e.g. "database" or "redis".
+func (h *HealthChecker) AddCheck(name string, check HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[name] = check
+}
+
+// Register registers the health service on s.
+func (h *HealthChecker) Register(s *grpc.Server) {
+	healthpb.RegisterHealthServer(s, h.server)
+}
+
+// Start runs the checks once, then every interval until Stop is called.
+func (h *HealthChecker) Start() {
+	h.RunChecks(context.Background())
+	go func() {
+		ticker := time.NewTicker(h.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				h.RunChecks(context.Background())
+			case <-h.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the checks and reports every service as NOT_SERVING, so clients
+// move away before the server shuts down.
+func (h *HealthChecker) Stop() {
+	h.stopOnce.Do(func() {
+		close(h.stop)
+		h.server.Shutdown()
+	})
+}
+
+// RunChecks runs every check concurrently and updates the served statuses.
+func (h *HealthChecker) RunChecks(ctx context.Context) {
+	h.mu.Lock()
+	checks := make(map[string]HealthCheck, len(h.checks))
+	for name, check := range h.checks {
+		checks[name] = check
+	}
+	h.mu.Unlock()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		serving = true
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check HealthCheck) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+
+			status := healthpb.HealthCheckResponse_SERVING
+			if err := check(checkCtx); err != nil {
+				status = healthpb.HealthCheckResponse_NOT_SERVING
+				mu.Lock()
+				serving = false
+				mu.Unlock()
+			}
+			h.server.SetServingStatus(name, status)
+		}(name, check)
+	}
+	wg.Wait()
+
+	if serving {
+		h.server.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	} else {
+		h.server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+}
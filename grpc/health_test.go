@@ -0,0 +1,66 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	coreGrpc "github.com/LukmanulHakim18/core/grpc"
+	"github.com/LukmanulHakim18/core/grpc/grpctest"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestHealthChecker(t *testing.T) {
+	redisErr := errors.New("redis down")
+	checker := coreGrpc.NewHealthChecker(time.Minute, time.Second)
+	checker.AddCheck("database", func(context.Context) error { return nil })
+	checker.AddCheck("redis", coreGrpc.PingCheck(func() error { return redisErr }))
+
+	srv := grpctest.NewServer()
+	checker.Register(srv.Server)
+	srv.Start()
+	defer srv.Close()
+
+	conn, err := srv.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("Check(%q) error = %v", service, err)
+		}
+		return res.Status
+	}
+
+	checker.Start()
+	defer checker.Stop()
+	tests := []struct {
+		service string
+		want    healthpb.HealthCheckResponse_ServingStatus
+	}{
+		{service: "database", want: healthpb.HealthCheckResponse_SERVING},
+		{service: "redis", want: healthpb.HealthCheckResponse_NOT_SERVING},
+		{service: "", want: healthpb.HealthCheckResponse_NOT_SERVING},
+	}
+	for _, tt := range tests {
+		if got := check(tt.service); got != tt.want {
+			t.Errorf("Check(%q) = %v, want %v", tt.service, got, tt.want)
+		}
+	}
+
+	redisErr = nil
+	checker.RunChecks(context.Background())
+	if got := check(""); got != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Check(\"\") = %v, want %v after recovery", got, healthpb.HealthCheckResponse_SERVING)
+	}
+
+	checker.Stop()
+	if got := check("database"); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("Check(\"database\") = %v, want %v after Stop", got, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+}
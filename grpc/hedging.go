@@ -0,0 +1,54 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/LukmanulHakim18/core/microservice"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// HedgingInterceptor is a gRPC client interceptor sending hedged attempts for slow unary calls.
+//
+// Hedged attempts reach another instance only when the connection balances
+// over several addresses, e.g. with the round_robin load balancing policy.
+type HedgingInterceptor struct {
+	hedger  *microservice.Hedger
+	methods map[string]bool
+}
+
+// NewHedgingInterceptor creates a new HedgingInterceptor instance.
+// Only the given full method names are hedged, or every method when none is given;
+// hedge idempotent methods only.
+func NewHedgingInterceptor(config *microservice.HedgingConfig, methods ...string) *HedgingInterceptor {
+	return &HedgingInterceptor{
+		hedger:  microservice.NewHedger(config),
+		methods: toKeySet(methods),
+	}
+}
+
+// UnaryClientInterceptor hedges unary gRPC client calls.
+func (h *HedgingInterceptor) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		msg, ok := reply.(proto.Message)
+		if !ok || (len(h.methods) > 0 && !h.methods[method]) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		// every attempt decodes into its own reply, the winner is merged into reply
+		res, err := h.hedger.Execute(ctx, method, func(ctx context.Context) (interface{}, error) {
+			attemptReply := msg.ProtoReflect().New().Interface()
+			if err := invoker(ctx, method, req, attemptReply, cc, opts...); err != nil {
+				return nil, err
+			}
+			return attemptReply, nil
+		}, nil)
+		if err != nil {
+			return err
+		}
+
+		proto.Reset(msg)
+		proto.Merge(msg, res.(proto.Message))
+		return nil
+	}
+}
@@ -0,0 +1,85 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/LukmanulHakim18/core/microservice"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LimiterInterceptor is a gRPC client interceptor applying an adaptive concurrency limit per external service.
+type LimiterInterceptor struct {
+	limiter *microservice.Limiter
+	label   prometheus.Labels
+}
+
+// NewLimiterInterceptor creates a new LimiterInterceptor instance.
+// When config.IsDropped is nil, only Unavailable, DeadlineExceeded and
+// ResourceExhausted errors decrease the limit.
+func NewLimiterInterceptor(externalServiceName string, config *microservice.LimiterConfig) *LimiterInterceptor {
+	if config == nil {
+		config = microservice.DefaultLimiterSetting(externalServiceName, 0)
+	}
+	// copy before defaulting so the caller's config is left untouched
+	cfg := *config
+	config = &cfg
+	if config.IsDropped == nil {
+		config.IsDropped = isDroppedGrpcError
+	}
+
+	appName := os.Getenv("APP_NAME")
+	if appName == "" {
+		appName = "unknown service"
+	}
+
+	podName := os.Getenv("POD_NAME")
+	if podName == "" {
+		podName = "unknown pod"
+	}
+
+	l := &LimiterInterceptor{
+		limiter: microservice.NewConcurrencyLimiter(config),
+		label: prometheus.Labels{
+			"app_name":              appName,
+			"pod_name":              podName,
+			"external_service_name": externalServiceName,
+		},
+	}
+	l.observe()
+	return l
+}
+
+// UnaryClientInterceptor rejects unary calls with ResourceExhausted once the limit is reached.
+func (l *LimiterInterceptor) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		_, err := l.limiter.Execute(ctx, func() (interface{}, error) {
+			l.observe()
+			return nil, invoker(ctx, method, req, reply, cc, opts...)
+		})
+		l.observe()
+
+		if errors.Is(err, microservice.ErrLimitExceeded) {
+			return status.Error(codes.ResourceExhausted, err.Error())
+		}
+		return err
+	}
+}
+
+func (l *LimiterInterceptor) observe() {
+	grpcConcurrencyLimit.With(l.label).Set(float64(l.limiter.Limit()))
+	grpcInFlight.With(l.label).Set(float64(l.limiter.InFlight()))
+}
+
+func isDroppedGrpcError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
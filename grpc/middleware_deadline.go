@@ -0,0 +1,126 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeadlineConfig configures the DeadlineInterceptor.
+type DeadlineConfig struct {
+	// DefaultTimeout applies to methods without a matching pattern, usually ClientOption.Timeout.
+	// Zero leaves those calls without timeout.
+	DefaultTimeout time.Duration
+	// MethodTimeouts maps method patterns to timeouts. A pattern is a full method
+	// name "/pkg.Service/Method", a whole service "/pkg.Service/*" or "*".
+	MethodTimeouts map[string]time.Duration
+	// SafetyMargin is kept from the inbound deadline so the caller has time to handle the reply
+	SafetyMargin time.Duration
+	// MinBudget is the smallest remaining budget a call is still sent with
+	MinBudget time.Duration
+}
+
+// DeadlineInterceptor is a gRPC client interceptor applying per-method timeouts within the inbound deadline.
+type DeadlineInterceptor struct {
+	config DeadlineConfig
+}
+
+// NewDeadlineInterceptor creates a new DeadlineInterceptor instance.
+func NewDeadlineInterceptor(config DeadlineConfig) *DeadlineInterceptor {
+	return &DeadlineInterceptor{
+		config: config,
+	}
+}
+
+// UnaryClientInterceptor sets the deadline of unary gRPC client calls and fails fast
+// with DeadlineExceeded when the remaining budget is too small.
+func (d *DeadlineInterceptor) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		budget, err := d.budget(ctx, method)
+		if err != nil {
+			return err
+		}
+		if budget > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, budget)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor sets the deadline of streaming gRPC client calls, covering the
+// whole stream, and fails fast with DeadlineExceeded when the remaining budget is too small.
+func (d *DeadlineInterceptor) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		budget, err := d.budget(ctx, method)
+		if err != nil {
+			return nil, err
+		}
+		if budget <= 0 {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		ctx, cancel := context.WithTimeout(ctx, budget)
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		return &deadlineClientStream{ClientStream: stream, serverStreams: desc.ServerStreams, cancel: cancel}, nil
+	}
+}
+
+// deadlineClientStream releases the timeout of the stream once the stream ends.
+type deadlineClientStream struct {
+	grpc.ClientStream
+	serverStreams bool
+	cancel        context.CancelFunc
+}
+
+func (s *deadlineClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	// the stream ends on an error, io.EOF included, or after the only reply of a client stream
+	if err != nil || !s.serverStreams {
+		s.cancel()
+	}
+	return err
+}
+
+// budget returns the timeout of the call, zero meaning no timeout.
+func (d *DeadlineInterceptor) budget(ctx context.Context, method string) (time.Duration, error) {
+	timeout := d.timeout(method)
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return timeout, nil
+	}
+
+	remaining := time.Until(deadline) - d.config.SafetyMargin
+	if remaining <= 0 || remaining < d.config.MinBudget {
+		return 0, status.Errorf(codes.DeadlineExceeded, "remaining deadline budget %s is too small to call %s", remaining, method)
+	}
+	if timeout > 0 && timeout < remaining {
+		return timeout, nil
+	}
+	return remaining, nil
+}
+
+// timeout returns the timeout of the most specific pattern matching method.
+func (d *DeadlineInterceptor) timeout(method string) time.Duration {
+	if timeout, ok := d.config.MethodTimeouts[method]; ok {
+		return timeout
+	}
+	if i := strings.LastIndex(method, "/"); i > 0 {
+		if timeout, ok := d.config.MethodTimeouts[method[:i]+"/*"]; ok {
+			return timeout
+		}
+	}
+	if timeout, ok := d.config.MethodTimeouts["*"]; ok {
+		return timeout
+	}
+	return d.config.DefaultTimeout
+}
@@ -0,0 +1,185 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDeadlineInterceptor_UnaryClientInterceptor(t *testing.T) {
+	config := DeadlineConfig{
+		DefaultTimeout: 5 * time.Second,
+		MethodTimeouts: map[string]time.Duration{
+			"/fare.FareService/Estimate": time.Second,
+			"/fare.FareService/*":        2 * time.Second,
+		},
+		SafetyMargin: 100 * time.Millisecond,
+		MinBudget:    50 * time.Millisecond,
+	}
+	tests := []struct {
+		name         string
+		method       string
+		inbound      time.Duration
+		wantTimeout  time.Duration
+		wantCode     codes.Code
+		wantDeadline bool
+	}{
+		{
+			name:         "exact method",
+			method:       "/fare.FareService/Estimate",
+			wantTimeout:  time.Second,
+			wantDeadline: true,
+		},
+		{
+			name:         "service pattern",
+			method:       "/fare.FareService/Quote",
+			wantTimeout:  2 * time.Second,
+			wantDeadline: true,
+		},
+		{
+			name:         "default timeout",
+			method:       "/user.UserService/Get",
+			wantTimeout:  5 * time.Second,
+			wantDeadline: true,
+		},
+		{
+			name:         "inbound deadline minus margin",
+			method:       "/fare.FareService/Estimate",
+			inbound:      500 * time.Millisecond,
+			wantTimeout:  400 * time.Millisecond,
+			wantDeadline: true,
+		},
+		{
+			name:     "fail fast when budget is too small",
+			method:   "/fare.FareService/Estimate",
+			inbound:  120 * time.Millisecond,
+			wantCode: codes.DeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.inbound > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.inbound)
+				defer cancel()
+			}
+
+			var gotTimeout time.Duration
+			var gotDeadline bool
+			invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				var deadline time.Time
+				deadline, gotDeadline = ctx.Deadline()
+				gotTimeout = time.Until(deadline)
+				return nil
+			}
+			err := NewDeadlineInterceptor(config).UnaryClientInterceptor()(ctx, tt.method, nil, nil, nil, invoker)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("UnaryClientInterceptor() error = %v, want code %v", err, tt.wantCode)
+			}
+			if gotDeadline != tt.wantDeadline {
+				t.Fatalf("UnaryClientInterceptor() deadline set = %v, want %v", gotDeadline, tt.wantDeadline)
+			}
+			if tt.wantDeadline && (gotTimeout > tt.wantTimeout || gotTimeout < tt.wantTimeout-20*time.Millisecond) {
+				t.Errorf("UnaryClientInterceptor() timeout = %v, want %v", gotTimeout, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+// recvStream is a grpc.ClientStream whose RecvMsg returns err.
+type recvStream struct {
+	grpc.ClientStream
+	err error
+}
+
+func (s *recvStream) RecvMsg(m interface{}) error {
+	return s.err
+}
+
+func TestDeadlineInterceptor_StreamClientInterceptor(t *testing.T) {
+	config := DeadlineConfig{
+		MethodTimeouts: map[string]time.Duration{"/chat.ChatService/Watch": time.Second},
+		SafetyMargin:   100 * time.Millisecond,
+		MinBudget:      50 * time.Millisecond,
+	}
+	tests := []struct {
+		name         string
+		method       string
+		inbound      time.Duration
+		wantTimeout  time.Duration
+		wantCode     codes.Code
+		wantDeadline bool
+	}{
+		{
+			name:         "method timeout",
+			method:       "/chat.ChatService/Watch",
+			wantTimeout:  time.Second,
+			wantDeadline: true,
+		},
+		{
+			name:         "inbound deadline minus margin",
+			method:       "/chat.ChatService/Watch",
+			inbound:      500 * time.Millisecond,
+			wantTimeout:  400 * time.Millisecond,
+			wantDeadline: true,
+		},
+		{
+			name:   "no timeout",
+			method: "/chat.ChatService/Send",
+		},
+		{
+			name:     "fail fast when budget is too small",
+			method:   "/chat.ChatService/Watch",
+			inbound:  120 * time.Millisecond,
+			wantCode: codes.DeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.inbound > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.inbound)
+				defer cancel()
+			}
+
+			var streamCtx context.Context
+			streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				streamCtx = ctx
+				return &recvStream{err: io.EOF}, nil
+			}
+			stream, err := NewDeadlineInterceptor(config).StreamClientInterceptor()(ctx, &grpc.StreamDesc{ServerStreams: true}, nil, tt.method, streamer)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("StreamClientInterceptor() error = %v, want code %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			deadline, gotDeadline := streamCtx.Deadline()
+			if gotDeadline != tt.wantDeadline {
+				t.Fatalf("StreamClientInterceptor() deadline set = %v, want %v", gotDeadline, tt.wantDeadline)
+			}
+			if !tt.wantDeadline {
+				return
+			}
+			if gotTimeout := time.Until(deadline); gotTimeout > tt.wantTimeout || gotTimeout < tt.wantTimeout-20*time.Millisecond {
+				t.Errorf("StreamClientInterceptor() timeout = %v, want %v", gotTimeout, tt.wantTimeout)
+			}
+			if streamCtx.Err() != nil {
+				t.Fatalf("StreamClientInterceptor() cancelled the stream before it ended")
+			}
+			if err := stream.RecvMsg(nil); err != io.EOF {
+				t.Fatalf("RecvMsg() error = %v, want %v", err, io.EOF)
+			}
+			if streamCtx.Err() == nil {
+				t.Errorf("StreamClientInterceptor() did not release the timeout once the stream ended")
+			}
+		})
+	}
+}
@@ -5,20 +5,84 @@ import (
 	"time"
 
 	"github.com/LukmanulHakim18/core/logger"
+	meta "github.com/LukmanulHakim18/core/metadata"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 // LoggerInterceptor is a gRPC client interceptor for logging requests and responses.
 type LoggerInterceptor struct {
-	logger *logger.Logger
+	logger     *logger.Logger
+	redactor   *payloadRedactor
+	logPayload func(method string) bool
+}
+
+// LoggerOption configures a LoggerInterceptor.
+type LoggerOption func(l *LoggerInterceptor)
+
+// WithRedactedFields replaces the proto field names whose values are hidden in logged payloads.
+// Fields marked with the debug_redact option are always hidden.
+func WithRedactedFields(names ...string) LoggerOption {
+	return func(l *LoggerInterceptor) {
+		l.redactor.fields = toKeySet(names)
+	}
+}
+
+// WithRedactFunc hides every field for which fn returns true, e.g. fields
+// annotated with a custom proto field option.
+func WithRedactFunc(fn func(fd protoreflect.FieldDescriptor) bool) LoggerOption {
+	return func(l *LoggerInterceptor) {
+		l.redactor.fn = fn
+	}
+}
+
+// WithRedactedMetadata replaces the metadata keys whose values are hidden in logs.
+func WithRedactedMetadata(keys ...string) LoggerOption {
+	return func(l *LoggerInterceptor) {
+		l.redactor.metadata = toKeySet(keys)
+	}
+}
+
+// WithMaxPayloadSize truncates logged payloads to size bytes, zero disables truncation.
+func WithMaxPayloadSize(size int) LoggerOption {
+	return func(l *LoggerInterceptor) {
+		l.redactor.maxSize = size
+	}
+}
+
+// WithPayloadLogging decides per full method name whether request and reply payloads are logged.
+func WithPayloadLogging(fn func(method string) bool) LoggerOption {
+	return func(l *LoggerInterceptor) {
+		l.logPayload = fn
+	}
+}
+
+// WithoutPayloadLogging disables payload logging for the given full method names.
+func WithoutPayloadLogging(methods ...string) LoggerOption {
+	disabled := toKeySet(methods)
+	return WithPayloadLogging(func(method string) bool {
+		return !disabled[method]
+	})
 }
 
 // NewLoggerInterceptor creates a new LoggerInterceptor instance.
-func NewLoggerInterceptor(logger *logger.Logger) *LoggerInterceptor {
-	return &LoggerInterceptor{
+// By default the token and user-info metadata and the password, pin, otp
+// and token fields are redacted and payloads are truncated to 10000 bytes.
+func NewLoggerInterceptor(logger *logger.Logger, opts ...LoggerOption) *LoggerInterceptor {
+	l := &LoggerInterceptor{
 		logger: logger,
+		redactor: &payloadRedactor{
+			fields:   toKeySet([]string{"password", "pin", "otp", "token"}),
+			metadata: toKeySet([]string{meta.MetadataToken, meta.MetadataUserInfo}),
+			maxSize:  10000,
+		},
+		logPayload: func(string) bool { return true },
+	}
+	for _, opt := range opts {
+		opt(l)
 	}
+	return l
 }
 
 // UnaryClientInterceptor logs details for unary gRPC client calls.
@@ -30,14 +94,15 @@ func (l *LoggerInterceptor) UnaryClientInterceptor() grpc.UnaryClientInterceptor
 		md, _ := metadata.FromOutgoingContext(ctx)
 
 		// Log request details
-		l.logger.InfoWithContext(ctx, "Sending gRPC request",
-			logger.ConvertMapToFields(map[string]interface{}{
-				"method":    method,
-				"metadata":  md,
-				"req_body":  req,
-				"grpc_type": "unary",
-			})...,
-		)
+		reqFields := map[string]interface{}{
+			"method":    method,
+			"metadata":  l.redactor.redactMetadata(md),
+			"grpc_type": "unary",
+		}
+		if l.logPayload(method) {
+			reqFields["req_body"] = l.redactor.formatPayload(req)
+		}
+		l.logger.InfoWithContext(ctx, "Sending gRPC request", logger.ConvertMapToFields(reqFields)...)
 
 		// Execute the gRPC call
 		err := invoker(ctx, method, req, reply, cc, opts...)
@@ -57,15 +122,16 @@ func (l *LoggerInterceptor) UnaryClientInterceptor() grpc.UnaryClientInterceptor
 		}
 
 		// Log response details
-		l.logger.InfoWithContext(ctx, "Received gRPC response",
-			logger.ConvertMapToFields(map[string]interface{}{
-				"method":      method,
-				"status":      "OK",
-				"duration_ms": duration.Milliseconds(),
-				"grpc_type":   "unary",
-				"res_body":    reply,
-			})...,
-		)
+		resFields := map[string]interface{}{
+			"method":      method,
+			"status":      "OK",
+			"duration_ms": duration.Milliseconds(),
+			"grpc_type":   "unary",
+		}
+		if l.logPayload(method) {
+			resFields["res_body"] = l.redactor.formatPayload(reply)
+		}
+		l.logger.InfoWithContext(ctx, "Received gRPC response", logger.ConvertMapToFields(resFields)...)
 
 		return nil
 	}
@@ -83,7 +149,7 @@ func (l *LoggerInterceptor) StreamClientInterceptor() grpc.StreamClientIntercept
 		l.logger.InfoWithContext(ctx, "Starting gRPC stream",
 			logger.ConvertMapToFields(map[string]interface{}{
 				"method":    method,
-				"metadata":  md,
+				"metadata":  l.redactor.redactMetadata(md),
 				"grpc_type": "stream",
 			})...,
 		)
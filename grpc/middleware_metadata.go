@@ -0,0 +1,101 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	meta "github.com/LukmanulHakim18/core/metadata"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataInterceptor is a gRPC client interceptor that forwards incoming metadata to outgoing calls.
+type MetadataInterceptor struct {
+	allowed map[string]bool
+	denied  map[string]bool
+}
+
+// MetadataOption configures a MetadataInterceptor.
+type MetadataOption func(m *MetadataInterceptor)
+
+// WithAllowedMetadata replaces the list of keys that may be forwarded.
+// By default every key in metadata.ListOfMetadataKey is allowed.
+func WithAllowedMetadata(keys ...string) MetadataOption {
+	return func(m *MetadataInterceptor) {
+		m.allowed = toKeySet(keys)
+	}
+}
+
+// WithDeniedMetadata replaces the list of keys that are never forwarded,
+// even when they are allowed. By default only the token is denied.
+func WithDeniedMetadata(keys ...string) MetadataOption {
+	return func(m *MetadataInterceptor) {
+		m.denied = toKeySet(keys)
+	}
+}
+
+// NewMetadataInterceptor creates a new MetadataInterceptor instance.
+func NewMetadataInterceptor(opts ...MetadataOption) *MetadataInterceptor {
+	m := &MetadataInterceptor{
+		allowed: toKeySet(meta.ListOfMetadataKey),
+		denied:  toKeySet([]string{meta.MetadataToken}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// UnaryClientInterceptor forwards metadata for unary gRPC client calls.
+func (m *MetadataInterceptor) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(m.outgoingContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor forwards metadata for streaming gRPC client calls.
+func (m *MetadataInterceptor) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(m.outgoingContext(ctx), desc, cc, method, opts...)
+	}
+}
+
+// outgoingContext copies the allowed incoming metadata into the outgoing metadata.
+// Keys already set on the outgoing metadata take precedence over incoming ones.
+func (m *MetadataInterceptor) outgoingContext(ctx context.Context) context.Context {
+	out, _ := metadata.FromOutgoingContext(ctx)
+	out = out.Copy()
+	explicitTraceContext := len(out.Get(meta.MetadataTraceparent)) > 0 || len(out.Get(meta.MetadataB3)) > 0
+
+	in, _ := metadata.FromIncomingContext(ctx)
+	for k, v := range in {
+		k = strings.ToLower(k)
+		if !m.allowed[k] || m.denied[k] || len(out.Get(k)) > 0 {
+			continue
+		}
+		out.Set(k, v...)
+	}
+
+	// trace-id is always forwarded so the next hop logs with the same id, it is only
+	// shared by fan-out calls when the request went through InitiateTraceId
+	if len(out.Get(meta.MetadataTraceId)) == 0 {
+		out.Set(meta.MetadataTraceId, meta.TraceIdFromContext(ctx))
+	}
+
+	// the active APM span continues the trace, so the next hop becomes its child
+	if !explicitTraceContext && (m.allowed[meta.MetadataTraceparent] || m.allowed[meta.MetadataB3]) {
+		if tc, ok := meta.TraceContextFromContext(ctx); ok {
+			meta.SetTraceContext(out, tc)
+		}
+	}
+
+	return metadata.NewOutgoingContext(ctx, out)
+}
+
+func toKeySet(keys []string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		set[strings.ToLower(k)] = true
+	}
+	return set
+}
@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	meta "github.com/LukmanulHakim18/core/metadata"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataServerInterceptor is a gRPC server interceptor that parses the incoming
+// metadata once per request, see metadata.InitiateMetadata. Requests whose user-info
+// is rejected, see metadata.UserInfoReject, fail with codes.Unauthenticated.
+type MetadataServerInterceptor struct{}
+
+// NewMetadataServerInterceptor creates a new MetadataServerInterceptor instance.
+func NewMetadataServerInterceptor() *MetadataServerInterceptor {
+	return &MetadataServerInterceptor{}
+}
+
+// UnaryServerInterceptor parses the metadata of unary gRPC requests.
+func (m *MetadataServerInterceptor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := initiateMetadata(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor parses the metadata of streaming gRPC requests.
+func (m *MetadataServerInterceptor) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := initiateMetadata(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &metadataServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// initiateMetadata fails the request when its user-info is rejected, other parse errors
+// are only logged as metadata.InitiateMetadata does.
+func initiateMetadata(ctx context.Context) (context.Context, error) {
+	ctx, err := meta.InitiateMetadataWithError(ctx)
+	var parseErr *meta.ParseError
+	if errors.As(err, &parseErr) && parseErr.UserInfoRejected() {
+		return nil, status.Error(codes.Unauthenticated, "invalid user-info signature")
+	}
+	if err != nil {
+		log.Printf("[InitiateMetadata] %s\n", err.Error())
+	}
+	return ctx, nil
+}
+
+type metadataServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *metadataServerStream) Context() context.Context {
+	return s.ctx
+}
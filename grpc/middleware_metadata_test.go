@@ -0,0 +1,181 @@
+package grpc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	meta "github.com/LukmanulHakim18/core/metadata"
+	"go.elastic.co/apm/module/apmhttp/v2"
+	"go.elastic.co/apm/v2"
+	"go.elastic.co/apm/v2/transport/transporttest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestMetadataInterceptor_UnaryClientInterceptor(t *testing.T) {
+	incoming := metadata.New(map[string]string{
+		meta.MetadataAcceptLang: "id",
+		meta.MetadataToken:      "secret",
+		meta.MetadataTraceId:    "trace-1",
+		"x-custom":              "custom",
+	})
+	tests := []struct {
+		name     string
+		opts     []MetadataOption
+		ctx      context.Context
+		want     map[string][]string
+		wantKeys []string
+	}{
+		{
+			name: "default forwards allowed keys and drops token",
+			ctx:  metadata.NewIncomingContext(context.Background(), incoming),
+			want: map[string][]string{
+				meta.MetadataAcceptLang: {"id"},
+				meta.MetadataTraceId:    {"trace-1"},
+			},
+		},
+		{
+			name: "deny list can be overridden",
+			opts: []MetadataOption{WithDeniedMetadata()},
+			ctx:  metadata.NewIncomingContext(context.Background(), incoming),
+			want: map[string][]string{
+				meta.MetadataAcceptLang: {"id"},
+				meta.MetadataToken:      {"secret"},
+				meta.MetadataTraceId:    {"trace-1"},
+			},
+		},
+		{
+			name: "outgoing metadata takes precedence",
+			opts: []MetadataOption{WithAllowedMetadata(meta.MetadataAcceptLang)},
+			ctx: metadata.AppendToOutgoingContext(
+				metadata.NewIncomingContext(context.Background(), incoming),
+				meta.MetadataTraceId, "trace-2",
+			),
+			want: map[string][]string{
+				meta.MetadataAcceptLang: {"id"},
+				meta.MetadataTraceId:    {"trace-2"},
+			},
+		},
+		{
+			name: "trace-id generated when missing",
+			ctx:  context.Background(),
+			want: map[string][]string{},
+			wantKeys: []string{
+				meta.MetadataTraceId,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got metadata.MD
+			invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				got, _ = metadata.FromOutgoingContext(ctx)
+				return nil
+			}
+			interceptor := NewMetadataInterceptor(tt.opts...).UnaryClientInterceptor()
+			if err := interceptor(tt.ctx, "/svc/Method", nil, nil, nil, invoker); err != nil {
+				t.Fatalf("UnaryClientInterceptor() error = %v", err)
+			}
+			for _, k := range tt.wantKeys {
+				if len(got.Get(k)) == 0 || got.Get(k)[0] == "" {
+					t.Errorf("UnaryClientInterceptor() missing %s", k)
+				}
+				delete(got, k)
+			}
+			if !reflect.DeepEqual(map[string][]string(got), tt.want) {
+				t.Errorf("UnaryClientInterceptor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadataInterceptor_fanOut(t *testing.T) {
+	var traceIds []string
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		traceIds = append(traceIds, md.Get(meta.MetadataTraceId)[0])
+		return nil
+	}
+	interceptor := NewMetadataInterceptor().UnaryClientInterceptor()
+	ctx := meta.InitiateTraceId(context.Background())
+	for i := 0; i < 2; i++ {
+		if err := interceptor(ctx, "/svc/Method", nil, nil, nil, invoker); err != nil {
+			t.Fatalf("UnaryClientInterceptor() error = %v", err)
+		}
+	}
+	if traceIds[0] != traceIds[1] || traceIds[0] != ctx.Value(meta.MetadataTraceId) {
+		t.Errorf("UnaryClientInterceptor() fan-out trace-ids = %v, want %v twice", traceIds, ctx.Value(meta.MetadataTraceId))
+	}
+}
+
+func TestMetadataInterceptor_traceContext(t *testing.T) {
+	tracer, err := apm.NewTracerOptions(apm.TracerOptions{Transport: transporttest.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tracer.Close()
+	tx := tracer.StartTransaction("test", "request")
+	defer tx.End()
+	span := tx.StartSpan("call", "external", nil)
+	defer span.End()
+	apmCtx := apm.ContextWithSpan(apm.ContextWithTransaction(context.Background(), tx), span)
+	spanTraceparent := apmhttp.FormatTraceparentHeader(span.TraceContext())
+
+	incomingTraceparent := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	tests := []struct {
+		name            string
+		ctx             context.Context
+		wantTraceId     string
+		wantTraceparent string
+	}{
+		{
+			name:            "active span becomes the parent",
+			ctx:             apmCtx,
+			wantTraceId:     tx.TraceContext().Trace.String(),
+			wantTraceparent: spanTraceparent,
+		},
+		{
+			name:            "incoming traceparent is forwarded without APM",
+			ctx:             metadata.NewIncomingContext(context.Background(), metadata.Pairs(meta.MetadataTraceparent, incomingTraceparent)),
+			wantTraceId:     "0af7651916cd43dd8448eb211c80319c",
+			wantTraceparent: incomingTraceparent,
+		},
+		{
+			name:            "active span wins over the incoming traceparent",
+			ctx:             metadata.NewIncomingContext(apmCtx, metadata.Pairs(meta.MetadataTraceparent, incomingTraceparent)),
+			wantTraceId:     tx.TraceContext().Trace.String(),
+			wantTraceparent: spanTraceparent,
+		},
+		{
+			name:            "incoming trace-id is kept next to the APM traceparent",
+			ctx:             metadata.NewIncomingContext(apmCtx, metadata.Pairs(meta.MetadataTraceId, "trace-1")),
+			wantTraceId:     "trace-1",
+			wantTraceparent: spanTraceparent,
+		},
+		{
+			name:            "explicit outgoing traceparent takes precedence",
+			ctx:             metadata.AppendToOutgoingContext(apmCtx, meta.MetadataTraceparent, incomingTraceparent),
+			wantTraceId:     tx.TraceContext().Trace.String(),
+			wantTraceparent: incomingTraceparent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got metadata.MD
+			invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				got, _ = metadata.FromOutgoingContext(ctx)
+				return nil
+			}
+			if err := NewMetadataInterceptor().UnaryClientInterceptor()(tt.ctx, "/svc/Method", nil, nil, nil, invoker); err != nil {
+				t.Fatalf("UnaryClientInterceptor() error = %v", err)
+			}
+			if traceId := got.Get(meta.MetadataTraceId); len(traceId) != 1 || traceId[0] != tt.wantTraceId {
+				t.Errorf("UnaryClientInterceptor() trace-id = %v, want %s", traceId, tt.wantTraceId)
+			}
+			if traceparent := got.Get(meta.MetadataTraceparent); len(traceparent) != 1 || traceparent[0] != tt.wantTraceparent {
+				t.Errorf("UnaryClientInterceptor() traceparent = %v, want %s", traceparent, tt.wantTraceparent)
+			}
+		})
+	}
+}
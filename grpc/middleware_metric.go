@@ -12,7 +12,8 @@ import (
 )
 
 var (
-	labelNames = []string{"app_name", "pod_name", "external_service_name", "method", "path", "status"}
+	labelNames        = []string{"app_name", "pod_name", "external_service_name", "method", "path", "status"}
+	limiterLabelNames = []string{"app_name", "pod_name", "external_service_name"}
 )
 
 var (
@@ -34,6 +35,24 @@ var (
 		},
 		labelNames,
 	)
+
+	// gRPC adaptive concurrency limit
+	grpcConcurrencyLimit = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "external_grpc_requests_concurrency_limit",
+			Help: "Current adaptive concurrency limit of gRPC requests",
+		},
+		limiterLabelNames,
+	)
+
+	// gRPC requests in flight
+	grpcInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "external_grpc_requests_in_flight",
+			Help: "Number of gRPC requests in flight",
+		},
+		limiterLabelNames,
+	)
 )
 
 type MetricInterceptor struct {
@@ -0,0 +1,114 @@
+package grpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const redactedValue = "[REDACTED]"
+
+// payloadRedactor formats request and reply payloads for logging with sensitive fields hidden.
+type payloadRedactor struct {
+	fields   map[string]bool
+	metadata map[string]bool
+	fn       func(fd protoreflect.FieldDescriptor) bool
+	maxSize  int
+}
+
+// formatPayload renders a payload as protojson, redacted and truncated to maxSize bytes.
+func (r *payloadRedactor) formatPayload(payload interface{}) string {
+	msg, ok := payload.(proto.Message)
+	if !ok {
+		return truncatePayload(fmt.Sprintf("%+v", payload), r.maxSize)
+	}
+	if !msg.ProtoReflect().IsValid() {
+		return ""
+	}
+
+	clone := proto.Clone(msg)
+	r.redactMessage(clone.ProtoReflect())
+	byt, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(clone)
+	if err != nil {
+		return fmt.Sprintf("unable to marshal payload: %s", err.Error())
+	}
+
+	// protojson randomizes whitespace, compact it so the logged payload is stable
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, byt); err == nil {
+		byt = buf.Bytes()
+	}
+	return truncatePayload(string(byt), r.maxSize)
+}
+
+// redactMetadata returns a copy of md with the values of sensitive keys hidden.
+func (r *payloadRedactor) redactMetadata(md metadata.MD) metadata.MD {
+	res := md.Copy()
+	for k := range res {
+		if r.metadata[k] {
+			res[k] = []string{redactedValue}
+		}
+	}
+	return res
+}
+
+func (r *payloadRedactor) redactMessage(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if r.isRedacted(fd) {
+			if fd.Kind() == protoreflect.StringKind && fd.Cardinality() != protoreflect.Repeated {
+				m.Set(fd, protoreflect.ValueOfString(redactedValue))
+			} else {
+				m.Clear(fd)
+			}
+			return true
+		}
+
+		switch {
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				r.redactMessage(list.Get(i).Message())
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				r.redactMessage(mv.Message())
+				return true
+			})
+		case !fd.IsMap() && fd.Message() != nil:
+			r.redactMessage(v.Message())
+		}
+		return true
+	})
+}
+
+// isRedacted reports whether a field is redacted by name, by the standard
+// debug_redact field option or by the custom redact function.
+func (r *payloadRedactor) isRedacted(fd protoreflect.FieldDescriptor) bool {
+	if r.fields[strings.ToLower(string(fd.Name()))] {
+		return true
+	}
+	if opts, ok := fd.Options().(*descriptorpb.FieldOptions); ok && opts.GetDebugRedact() {
+		return true
+	}
+	return r.fn != nil && r.fn(fd)
+}
+
+// Helper function to truncate long payloads
+func truncatePayload(input string, maxLength int) string {
+	if maxLength > 0 && len(input) > maxLength {
+		// back off to a rune boundary so the log stays valid UTF-8
+		for maxLength > 0 && !utf8.RuneStart(input[maxLength]) {
+			maxLength--
+		}
+		return input[:maxLength] + "..." // Add ellipsis to indicate truncation
+	}
+	return input
+}
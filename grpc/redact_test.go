@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestPayloadRedactor_formatPayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		redactor payloadRedactor
+		payload  interface{}
+		want     string
+	}{
+		{
+			name:     "redact field by name",
+			redactor: payloadRedactor{fields: toKeySet([]string{"domain"})},
+			payload:  &errdetails.ErrorInfo{Reason: "BB-0001", Domain: "500"},
+			want:     `{"reason":"BB-0001","domain":"[REDACTED]"}`,
+		},
+		{
+			name:     "redact nested repeated field",
+			redactor: payloadRedactor{fields: toKeySet([]string{"description"})},
+			payload: &errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: "pin", Description: "123456"},
+			}},
+			want: `{"field_violations":[{"field":"pin","description":"[REDACTED]"}]}`,
+		},
+		{
+			name: "redact map with custom function",
+			redactor: payloadRedactor{fn: func(fd protoreflect.FieldDescriptor) bool {
+				return fd.Name() == "metadata"
+			}},
+			payload: &errdetails.ErrorInfo{Reason: "BB-0001", Metadata: map[string]string{"token": "secret"}},
+			want:    `{"reason":"BB-0001"}`,
+		},
+		{
+			name:     "truncate payload",
+			redactor: payloadRedactor{maxSize: 10},
+			payload:  &errdetails.ErrorInfo{Reason: "BB-0001"},
+			want:     `{"reason":...`,
+		},
+		{
+			name:     "truncate payload on a rune boundary",
+			redactor: payloadRedactor{maxSize: 17},
+			payload:  &errdetails.ErrorInfo{Reason: "BB-ñño"},
+			want:     `{"reason":"BB-ñ...`,
+		},
+		{
+			name:     "nil message",
+			redactor: payloadRedactor{},
+			payload:  (*errdetails.ErrorInfo)(nil),
+			want:     "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.redactor.formatPayload(tt.payload); got != tt.want {
+				t.Errorf("payloadRedactor.formatPayload() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayloadRedactor_redactMetadata(t *testing.T) {
+	md := metadata.Pairs("token", "secret", "accept-language", "id")
+	r := payloadRedactor{metadata: toKeySet([]string{"token"})}
+
+	got := r.redactMetadata(md)
+	if got.Get("token")[0] != redactedValue || got.Get("accept-language")[0] != "id" {
+		t.Errorf("payloadRedactor.redactMetadata() = %v", got)
+	}
+	if md.Get("token")[0] != "secret" {
+		t.Errorf("payloadRedactor.redactMetadata() modified the original metadata")
+	}
+}
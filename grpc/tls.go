@@ -2,7 +2,6 @@ package grpc
 
 import (
 	"crypto/tls"
-	"crypto/x509"
 	"io/ioutil"
 
 	"google.golang.org/grpc/credentials"
@@ -22,7 +21,7 @@ func TLSCredentialFromFile(cacert, svcert, key string, mutual bool) (credentials
 		return nil, err
 	}
 
-	return tlsCredential(rawCaCert, cert, mutual), nil
+	return tlsCredential(rawCaCert, cert, mutual)
 }
 
 //TLSCredentialFromData returns transport credentials
@@ -34,7 +33,7 @@ func TLSCredentialFromData(cacert, svcert, key []byte, mutual bool) (credentials
 		return nil, err
 	}
 
-	return tlsCredential(cacert, cert, mutual), nil
+	return tlsCredential(cacert, cert, mutual)
 }
 
 //TLSCredentialFromKeyPair loads certificate from keypair and returns transport credentials
@@ -45,7 +44,7 @@ func TLSCredentialFromKeyPair(cacert string, cert tls.Certificate, mutual bool)
 		return nil, err
 	}
 
-	return tlsCredential(rawCaCert, cert, mutual), nil
+	return tlsCredential(rawCaCert, cert, mutual)
 }
 
 // TLSCredentialFromCertForClient loads certificate from file and returns transport credentials for client
@@ -59,18 +58,19 @@ func TLSCredentialFromCertForClient(certPath string) (credentials.TransportCrede
 		return nil, err
 	}
 
-	certPool := x509.NewCertPool()
-	if !certPool.AppendCertsFromPEM(pemServerCA) {
+	certPool, err := parseCertPool(pemServerCA)
+	if err != nil {
 		return nil, err
 	}
 
 	return credentials.NewClientTLSFromCert(certPool, ""), nil
 }
 
-
-func tlsCredential(cacert []byte, cert tls.Certificate, mutual bool) credentials.TransportCredentials {
-	caCertPool := x509.NewCertPool()
-	caCertPool.AppendCertsFromPEM(cacert)
+func tlsCredential(cacert []byte, cert tls.Certificate, mutual bool) (credentials.TransportCredentials, error) {
+	caCertPool, err := parseCertPool(cacert)
+	if err != nil {
+		return nil, err
+	}
 
 	tlsCfg := &tls.Config{
 		Certificates: []tls.Certificate{cert},
@@ -82,5 +82,5 @@ func tlsCredential(cacert []byte, cert tls.Certificate, mutual bool) credentials
 		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
 	}
 
-	return credentials.NewTLS(tlsCfg)
+	return credentials.NewTLS(tlsCfg), nil
 }
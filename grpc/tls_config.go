@@ -0,0 +1,294 @@
+package grpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slices"
+	"google.golang.org/grpc/credentials"
+)
+
+// TLSConfig describes the certificates and peer checks used to build transport credentials.
+// Files take precedence over PEM data. With ReloadInterval set, changed files are
+// loaded again on the next handshake, so rotated certificates apply without restart.
+type TLSConfig struct {
+	CACertFile string
+	CertFile   string
+	KeyFile    string
+
+	CACert []byte
+	Cert   []byte
+	Key    []byte
+
+	// ServerName overrides the name the server certificate is checked against
+	ServerName string
+	// MinVersion defaults to TLS 1.2
+	MinVersion uint16
+	// Mutual makes the server require and verify client certificates
+	Mutual bool
+	// AllowedSANs restricts peers to certificates carrying one of these DNS, IP, email or URI SANs,
+	// on a server they require Mutual
+	AllowedSANs []string
+	// AllowedSPIFFEIDs restricts peers to certificates carrying one of these spiffe:// URI SANs
+	AllowedSPIFFEIDs []string
+	// ReloadInterval is how often certificate files are checked for changes, zero disables reload
+	ReloadInterval time.Duration
+}
+
+// ServerCredentials returns transport credentials for a gRPC server.
+func (c TLSConfig) ServerCredentials() (credentials.TransportCredentials, error) {
+	cfg, err := c.ServerTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
+// ClientCredentials returns transport credentials for a gRPC client.
+func (c TLSConfig) ClientCredentials() (credentials.TransportCredentials, error) {
+	cfg, err := c.ClientTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
+// ServerTLSConfig returns the tls.Config of a server, a certificate is required.
+func (c TLSConfig) ServerTLSConfig() (*tls.Config, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	store, err := newCertStore(c)
+	if err != nil {
+		return nil, err
+	}
+	if store.cert == nil {
+		return nil, errors.New("tls: server certificate and key are required")
+	}
+	if !c.Mutual && (len(c.AllowedSANs) > 0 || len(c.AllowedSPIFFEIDs) > 0) {
+		return nil, errors.New("tls: allowed SANs and SPIFFE IDs require Mutual to verify client certificates")
+	}
+	if c.Mutual && store.pool == nil {
+		return nil, errors.New("tls: CA certificate is required to verify client certificates")
+	}
+
+	return &tls.Config{
+		MinVersion: c.minVersion(),
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := store.current()
+			cfg := &tls.Config{
+				MinVersion:   c.minVersion(),
+				Certificates: []tls.Certificate{*cert},
+				ClientCAs:    pool,
+			}
+			if c.Mutual {
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+				cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+					return verifyPeerIdentity(cs.PeerCertificates[0], c.AllowedSANs, c.AllowedSPIFFEIDs)
+				}
+			}
+			return cfg, nil
+		},
+	}, nil
+}
+
+// ClientTLSConfig returns the tls.Config of a client. The client certificate is optional,
+// without CA certificate the server is verified against the system roots.
+func (c TLSConfig) ClientTLSConfig() (*tls.Config, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	store, err := newCertStore(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion: c.minVersion(),
+		ServerName: c.ServerName,
+		// the chain is verified in VerifyConnection against the CA pool loaded last
+		InsecureSkipVerify: true,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := store.current()
+			if cert == nil {
+				return &tls.Certificate{}, nil
+			}
+			return cert, nil
+		},
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			_, pool := store.current()
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("tls: server did not present a certificate")
+			}
+			opts := x509.VerifyOptions{
+				Roots:         pool,
+				DNSName:       cs.ServerName,
+				Intermediates: x509.NewCertPool(),
+			}
+			for _, cert := range cs.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(cert)
+			}
+			if _, err := cs.PeerCertificates[0].Verify(opts); err != nil {
+				return err
+			}
+			return verifyPeerIdentity(cs.PeerCertificates[0], c.AllowedSANs, c.AllowedSPIFFEIDs)
+		},
+	}, nil
+}
+
+func (c TLSConfig) validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("tls: certificate file and key file must be set together")
+	}
+	if (len(c.Cert) == 0) != (len(c.Key) == 0) {
+		return errors.New("tls: certificate and key must be set together")
+	}
+	for _, id := range c.AllowedSPIFFEIDs {
+		if !strings.HasPrefix(id, "spiffe://") {
+			return fmt.Errorf("tls: invalid SPIFFE ID %q", id)
+		}
+	}
+	return nil
+}
+
+func (c TLSConfig) minVersion() uint16 {
+	if c.MinVersion == 0 {
+		return tls.VersionTLS12
+	}
+	return c.MinVersion
+}
+
+// certStore holds the certificate and CA pool, reloading them when their files change.
+type certStore struct {
+	config    TLSConfig
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+	modTimes  map[string]time.Time
+	lastCheck time.Time
+}
+
+func newCertStore(config TLSConfig) (*certStore, error) {
+	s := &certStore{config: config}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// current returns the certificate and CA pool, reloading changed files first.
+func (s *certStore) current() (*tls.Certificate, *x509.CertPool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.config.ReloadInterval > 0 && time.Since(s.lastCheck) >= s.config.ReloadInterval {
+		s.lastCheck = time.Now()
+		if s.changed() {
+			if err := s.load(); err != nil {
+				log.Printf("[TLSConfig] error when reload certificates, keep using the previous ones. error: %s\n", err.Error())
+			}
+		}
+	}
+	return s.cert, s.pool
+}
+
+func (s *certStore) load() error {
+	cfg := s.config
+	modTimes := map[string]time.Time{}
+	read := func(path string, data []byte) ([]byte, error) {
+		if path == "" {
+			return data, nil
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[path] = info.ModTime()
+		return os.ReadFile(path)
+	}
+
+	certPEM, err := read(cfg.CertFile, cfg.Cert)
+	if err != nil {
+		return err
+	}
+	keyPEM, err := read(cfg.KeyFile, cfg.Key)
+	if err != nil {
+		return err
+	}
+	caPEM, err := read(cfg.CACertFile, cfg.CACert)
+	if err != nil {
+		return err
+	}
+
+	var cert *tls.Certificate
+	if len(certPEM) > 0 {
+		pair, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return fmt.Errorf("tls: invalid certificate or key: %w", err)
+		}
+		cert = &pair
+	}
+
+	var pool *x509.CertPool
+	if len(caPEM) > 0 {
+		if pool, err = parseCertPool(caPEM); err != nil {
+			return err
+		}
+	}
+
+	s.cert, s.pool, s.modTimes = cert, pool, modTimes
+	s.lastCheck = time.Now()
+	return nil
+}
+
+func (s *certStore) changed() bool {
+	for path, modTime := range s.modTimes {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCertPool returns a pool of the PEM encoded certificates, failing when none is valid.
+func parseCertPool(pem []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("tls: no valid CA certificate found in PEM data")
+	}
+	return pool, nil
+}
+
+// verifyPeerIdentity checks the peer certificate against the allowed SANs and SPIFFE IDs,
+// every certificate is accepted when both lists are empty.
+func verifyPeerIdentity(cert *x509.Certificate, sans, spiffeIDs []string) error {
+	if len(sans) == 0 && len(spiffeIDs) == 0 {
+		return nil
+	}
+
+	names := append([]string{}, cert.DNSNames...)
+	names = append(names, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	for _, name := range names {
+		if slices.Contains(sans, name) {
+			return nil
+		}
+	}
+	for _, uri := range cert.URIs {
+		id := uri.String()
+		if slices.Contains(sans, id) || (uri.Scheme == "spiffe" && slices.Contains(spiffeIDs, id)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("tls: peer certificate %q matches none of the allowed SANs or SPIFFE IDs", cert.Subject.String())
+}
@@ -0,0 +1,244 @@
+package grpc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCert(t *testing.T, cn string, parent *testCert, dnsNames []string, uris ...string) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     dnsNames,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	for _, u := range uris {
+		parsed, _ := url.Parse(u)
+		tmpl.URIs = append(tmpl.URIs, parsed)
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+	}
+}
+
+func handshake(t *testing.T, server, client TLSConfig) error {
+	t.Helper()
+	serverCfg, err := server.ServerTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCfg, err := client.ClientTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	errc := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			errc <- err
+			return
+		}
+		defer conn.Close()
+		errc <- tls.Server(conn, serverCfg).Handshake()
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	clientTLS := tls.Client(conn, clientCfg)
+	clientErr := clientTLS.Handshake()
+	if clientErr == nil {
+		// the server reports a rejected client certificate after the client handshake in TLS 1.3
+		clientTLS.Read(make([]byte, 1))
+	}
+	conn.Close()
+	serverErr := <-errc
+	if clientErr != nil {
+		return clientErr
+	}
+	return serverErr
+}
+
+func TestTLSConfig_validation(t *testing.T) {
+	ca := newTestCert(t, "ca", nil, nil)
+	leaf := newTestCert(t, "leaf", ca, []string{"server.local"})
+
+	tests := []struct {
+		name   string
+		config TLSConfig
+		server bool
+	}{
+		{name: "invalid CA PEM", config: TLSConfig{CACert: []byte("not a certificate")}},
+		{name: "invalid key pair", config: TLSConfig{Cert: leaf.certPEM, Key: ca.keyPEM}},
+		{name: "certificate without key", config: TLSConfig{Cert: leaf.certPEM}},
+		{name: "invalid SPIFFE ID", config: TLSConfig{AllowedSPIFFEIDs: []string{"fare"}}},
+		{name: "server without certificate", config: TLSConfig{CACert: ca.certPEM}, server: true},
+		{name: "server allowed SANs without mutual", config: TLSConfig{Cert: leaf.certPEM, Key: leaf.keyPEM, AllowedSANs: []string{"client.local"}}, server: true},
+		{name: "server allowed SPIFFE IDs without mutual", config: TLSConfig{Cert: leaf.certPEM, Key: leaf.keyPEM, AllowedSPIFFEIDs: []string{"spiffe://example.org/client"}}, server: true},
+		{name: "missing CA file", config: TLSConfig{CACertFile: filepath.Join(t.TempDir(), "ca.pem")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.server {
+				_, err = tt.config.ServerCredentials()
+			} else {
+				_, err = tt.config.ClientCredentials()
+			}
+			if err == nil {
+				t.Errorf("TLSConfig credentials error = nil, want error")
+			}
+		})
+	}
+
+	if _, err := TLSCredentialFromData([]byte("not a certificate"), leaf.certPEM, leaf.keyPEM, false); err == nil {
+		t.Errorf("TLSCredentialFromData() error = nil, want error")
+	}
+}
+
+func TestTLSConfig_handshake(t *testing.T) {
+	ca := newTestCert(t, "ca", nil, nil)
+	serverCert := newTestCert(t, "server", ca, []string{"server.local"}, "spiffe://bb.local/fare")
+	clientCert := newTestCert(t, "client", ca, nil, "spiffe://bb.local/gateway")
+	otherCA := newTestCert(t, "other-ca", nil, nil)
+
+	server := TLSConfig{CACert: ca.certPEM, Cert: serverCert.certPEM, Key: serverCert.keyPEM, Mutual: true}
+	client := TLSConfig{CACert: ca.certPEM, Cert: clientCert.certPEM, Key: clientCert.keyPEM, ServerName: "server.local"}
+
+	tests := []struct {
+		name    string
+		server  func(c TLSConfig) TLSConfig
+		client  func(c TLSConfig) TLSConfig
+		wantErr bool
+	}{
+		{
+			name: "mutual TLS",
+		},
+		{
+			name:   "allowed SPIFFE IDs on both sides",
+			server: func(c TLSConfig) TLSConfig { c.AllowedSPIFFEIDs = []string{"spiffe://bb.local/gateway"}; return c },
+			client: func(c TLSConfig) TLSConfig { c.AllowedSPIFFEIDs = []string{"spiffe://bb.local/fare"}; return c },
+		},
+		{
+			name:    "server SPIFFE ID not allowed",
+			client:  func(c TLSConfig) TLSConfig { c.AllowedSPIFFEIDs = []string{"spiffe://bb.local/payment"}; return c },
+			wantErr: true,
+		},
+		{
+			name:    "client SAN not allowed",
+			server:  func(c TLSConfig) TLSConfig { c.AllowedSANs = []string{"gateway.local"}; return c },
+			wantErr: true,
+		},
+		{
+			name:    "server name mismatch",
+			client:  func(c TLSConfig) TLSConfig { c.ServerName = "other.local"; return c },
+			wantErr: true,
+		},
+		{
+			name:    "unknown CA",
+			client:  func(c TLSConfig) TLSConfig { c.CACert = otherCA.certPEM; return c },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, c := server, client
+			if tt.server != nil {
+				s = tt.server(s)
+			}
+			if tt.client != nil {
+				c = tt.client(c)
+			}
+			if err := handshake(t, s, c); (err != nil) != tt.wantErr {
+				t.Errorf("handshake() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCertStore_reload(t *testing.T) {
+	ca := newTestCert(t, "ca", nil, nil)
+	first := newTestCert(t, "first", ca, []string{"server.local"})
+	second := newTestCert(t, "second", ca, []string{"server.local"})
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	write := func(c *testCert, modTime time.Time) {
+		os.WriteFile(certFile, c.certPEM, 0o600)
+		os.WriteFile(keyFile, c.keyPEM, 0o600)
+		os.Chtimes(certFile, modTime, modTime)
+		os.Chtimes(keyFile, modTime, modTime)
+	}
+	write(first, time.Now().Add(-time.Minute))
+
+	store, err := newCertStore(TLSConfig{CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Nanosecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write(second, time.Now())
+	cert, _ := store.current()
+	if leaf, _ := x509.ParseCertificate(cert.Certificate[0]); leaf.Subject.CommonName != "second" {
+		t.Errorf("certStore.current() = %s, want the reloaded certificate", leaf.Subject.CommonName)
+	}
+
+	os.WriteFile(certFile, []byte("broken"), 0o600)
+	os.Chtimes(certFile, time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+	cert, _ = store.current()
+	if leaf, _ := x509.ParseCertificate(cert.Certificate[0]); leaf.Subject.CommonName != "second" {
+		t.Errorf("certStore.current() = %s, want the previous certificate after a failed reload", leaf.Subject.CommonName)
+	}
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/LukmanulHakim18/core/microservice"
+)
+
+type hedgingMiddleware struct {
+	next   Middleware
+	hedger *microservice.Hedger
+}
+
+// NewHedgingMiddleware sends hedged attempts for slow GET and HEAD requests,
+// other methods are passed through untouched.
+func NewHedgingMiddleware(config *microservice.HedgingConfig) Middleware {
+	return &hedgingMiddleware{
+		hedger: microservice.NewHedger(config),
+	}
+}
+
+func (h *hedgingMiddleware) Process(ctx context.Context, client *http.Client, req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return h.next.Process(ctx, client, req)
+	}
+
+	res, release, err := h.hedger.ExecuteWithRelease(ctx, req.Method+" "+req.URL.Path, func(ctx context.Context) (interface{}, error) {
+		return h.next.Process(ctx, client, req.Clone(ctx))
+	}, closeResponse)
+
+	returned, ok := res.(*http.Response)
+	if !ok || returned == nil || returned.Body == nil {
+		release()
+		if ok {
+			return returned, err
+		}
+		return nil, err
+	}
+	// the body is read through the winning attempt's context, release it once the body is closed
+	returned.Body = &releaseOnClose{ReadCloser: returned.Body, release: release}
+	return returned, err
+}
+
+func (h *hedgingMiddleware) SetNext(next Middleware) {
+	h.next = next
+}
+
+// releaseOnClose cancels the context of the winning attempt when its body is closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	release context.CancelFunc
+}
+
+func (r *releaseOnClose) Close() error {
+	defer r.release()
+	return r.ReadCloser.Close()
+}
+
+// closeResponse releases the connection of a losing attempt.
+func closeResponse(res interface{}) {
+	if r, ok := res.(*http.Response); ok && r != nil && r.Body != nil {
+		r.Body.Close()
+	}
+}
@@ -0,0 +1,45 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/LukmanulHakim18/core/microservice"
+)
+
+func TestHedgingMiddleware_streamedBody(t *testing.T) {
+	body := bytes.Repeat([]byte("a"), 1<<20)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body[:4])
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		w.Write(body[4:])
+	}))
+	defer server.Close()
+
+	m := NewHedgingMiddleware(&microservice.HedgingConfig{Delay: time.Second})
+	m.SetNext(&Runner{})
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := m.Process(context.Background(), server.Client(), req)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	got, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("reading the body error = %v", err)
+	}
+	if len(got) != len(body) {
+		t.Errorf("body length = %d, want %d", len(got), len(body))
+	}
+	if err := res.Body.Close(); err != nil {
+		t.Errorf("closing the body error = %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/LukmanulHakim18/core/microservice"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type limiterMiddleware struct {
+	next    Middleware
+	limiter *microservice.Limiter
+	label   prometheus.Labels
+}
+
+// DroppedResponseError is passed to LimiterConfig.IsDropped for a 5xx or 429 response,
+// it is never returned to the caller who gets the response instead.
+type DroppedResponseError struct {
+	StatusCode int
+}
+
+func (e *DroppedResponseError) Error() string {
+	return fmt.Sprintf("response status %d", e.StatusCode)
+}
+
+// NewLimiterMiddleware applies an adaptive concurrency limit to requests sent to externalServiceName.
+// Once the limit is reached, requests fail with microservice.ErrLimitExceeded.
+// Transport errors and 5xx or 429 responses decrease the limit, see DroppedResponseError.
+func NewLimiterMiddleware(externalServiceName string, config *microservice.LimiterConfig) Middleware {
+	if config == nil {
+		config = microservice.DefaultLimiterSetting(externalServiceName, 0)
+	}
+	// copy before the limiter sets its defaults so the caller's config is left untouched
+	cfg := *config
+	config = &cfg
+
+	appName := os.Getenv("APP_NAME")
+	if appName == "" {
+		appName = "unknown service"
+	}
+
+	podName := os.Getenv("POD_NAME")
+	if podName == "" {
+		podName = "unknown pod"
+	}
+
+	l := &limiterMiddleware{
+		limiter: microservice.NewConcurrencyLimiter(config),
+		label: prometheus.Labels{
+			"app_name":              appName,
+			"pod_name":              podName,
+			"external_service_name": externalServiceName,
+		},
+	}
+	l.observe()
+	return l
+}
+
+func (l *limiterMiddleware) Process(ctx context.Context, client *http.Client, req *http.Request) (*http.Response, error) {
+	res, err := l.limiter.Execute(ctx, func() (interface{}, error) {
+		l.observe()
+		res, err := l.next.Process(ctx, client, req)
+		if err == nil && res != nil && (res.StatusCode >= http.StatusInternalServerError || res.StatusCode == http.StatusTooManyRequests) {
+			return res, &DroppedResponseError{StatusCode: res.StatusCode}
+		}
+		return res, err
+	})
+	l.observe()
+
+	var dropped *DroppedResponseError
+	if errors.As(err, &dropped) {
+		err = nil
+	}
+
+	returned, ok := res.(*http.Response)
+	if ok {
+		return returned, err
+	}
+	return nil, err
+}
+
+func (l *limiterMiddleware) SetNext(next Middleware) {
+	l.next = next
+}
+
+func (l *limiterMiddleware) observe() {
+	apiConcurrencyLimit.With(l.label).Set(float64(l.limiter.Limit()))
+	apiInFlight.With(l.label).Set(float64(l.limiter.InFlight()))
+}
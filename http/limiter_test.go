@@ -0,0 +1,54 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/LukmanulHakim18/core/microservice"
+)
+
+func TestLimiterMiddleware_Process(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantLimit int
+	}{
+		{name: "success keeps the limit", status: http.StatusOK, wantLimit: 10},
+		{name: "client error keeps the limit", status: http.StatusNotFound, wantLimit: 10},
+		{name: "too many requests decreases the limit", status: http.StatusTooManyRequests, wantLimit: 5},
+		{name: "server error decreases the limit", status: http.StatusServiceUnavailable, wantLimit: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			config := &microservice.LimiterConfig{InitialLimit: 10, LatencyThreshold: time.Second, BackoffRatio: 0.5}
+			m := NewLimiterMiddleware("limiter-test", config)
+			m.SetNext(&Runner{})
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := m.Process(context.Background(), server.Client(), req)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			res.Body.Close()
+			if res.StatusCode != tt.status {
+				t.Errorf("Process() status = %d, want %d", res.StatusCode, tt.status)
+			}
+			if got := m.(*limiterMiddleware).limiter.Limit(); got != tt.wantLimit {
+				t.Errorf("Limit() = %d, want %d", got, tt.wantLimit)
+			}
+			if config.Name != "" || config.MaxLimit != 0 || config.IsDropped != nil {
+				t.Errorf("NewLimiterMiddleware() changed the caller's config = %+v", config)
+			}
+		})
+	}
+}
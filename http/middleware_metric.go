@@ -11,7 +11,8 @@ import (
 )
 
 var (
-	labelNames = []string{"app_name", "pod_name", "external_service_name", "method", "path", "status"}
+	labelNames        = []string{"app_name", "pod_name", "external_service_name", "method", "path", "status"}
+	limiterLabelNames = []string{"app_name", "pod_name", "external_service_name"}
 )
 
 type metricMiddleware struct {
@@ -94,4 +95,20 @@ var (
 		},
 		labelNames,
 	)
+
+	apiConcurrencyLimit = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "external_api_requests_concurrency_limit",
+			Help: "Current adaptive concurrency limit of API requests",
+		},
+		limiterLabelNames,
+	)
+
+	apiInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "external_api_requests_in_flight",
+			Help: "Number of API requests in flight",
+		},
+		limiterLabelNames,
+	)
 )
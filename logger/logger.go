@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/LukmanulHakim18/core/metadata"
+	"go.elastic.co/apm/v2"
 	"go.elastic.co/ecszap"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -139,6 +140,15 @@ func (l *Logger) logWithContext(ctx context.Context, logFunc func(string, ...zap
 	}
 
 	zapFields := append(convertToZapFields(fields), zap.String("trace-id", traceID))
+
+	// Correlate logs with the active APM transaction and span
+	if tx := apm.TransactionFromContext(ctx); tx != nil {
+		tc := tx.TraceContext()
+		zapFields = append(zapFields, zap.String("trace.id", tc.Trace.String()), zap.String("transaction.id", tc.Span.String()))
+		if span := apm.SpanFromContext(ctx); span != nil {
+			zapFields = append(zapFields, zap.String("span.id", span.TraceContext().Span.String()))
+		}
+	}
 	logFunc(message, zapFields...)
 }
 
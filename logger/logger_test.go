@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LukmanulHakim18/core/metadata"
+	"go.elastic.co/apm/v2"
+	"go.elastic.co/apm/v2/transport/transporttest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogger_InfoWithContext(t *testing.T) {
+	tracer, err := apm.NewTracerOptions(apm.TracerOptions{Transport: transporttest.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tracer.Close()
+	tx := tracer.StartTransaction("test", "request")
+	defer tx.End()
+	span := tx.StartSpan("call", "external", nil)
+	defer span.End()
+	txCtx := apm.ContextWithTransaction(context.WithValue(context.Background(), metadata.MetadataTraceId, "trace-1"), tx)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want map[string]interface{}
+	}{
+		{
+			name: "no APM transaction",
+			ctx:  context.Background(),
+			want: map[string]interface{}{"trace-id": "unknown-trace-id"},
+		},
+		{
+			name: "transaction",
+			ctx:  txCtx,
+			want: map[string]interface{}{
+				"trace-id":       "trace-1",
+				"trace.id":       tx.TraceContext().Trace.String(),
+				"transaction.id": tx.TraceContext().Span.String(),
+			},
+		},
+		{
+			name: "span",
+			ctx:  apm.ContextWithSpan(txCtx, span),
+			want: map[string]interface{}{
+				"trace-id":       "trace-1",
+				"trace.id":       tx.TraceContext().Trace.String(),
+				"transaction.id": tx.TraceContext().Span.String(),
+				"span.id":        span.TraceContext().Span.String(),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.DebugLevel)
+			l := &Logger{loggerWithContext: zap.New(core)}
+			l.InfoWithContext(tt.ctx, "message")
+
+			entries := logs.All()
+			if len(entries) != 1 {
+				t.Fatalf("InfoWithContext() logged %d entries, want 1", len(entries))
+			}
+			got := entries[0].ContextMap()
+			if len(got) != len(tt.want) {
+				t.Errorf("InfoWithContext() fields = %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("InfoWithContext() %s = %v, want %v", k, got[k], v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/LukmanulHakim18/core/constant"
+	"google.golang.org/grpc/metadata"
+)
+
+// BuildOption configures the metadata built from Metadata.
+type BuildOption func(b *builder)
+
+type builder struct {
+	keyring *Keyring
+}
+
+// WithSigningKeyring signs the user-info with keyring, e.g. for cron jobs and pubsub
+// consumers building Metadata themselves while services verify the user-info.
+func WithSigningKeyring(keyring *Keyring) BuildOption {
+	return func(b *builder) {
+		b.keyring = keyring
+	}
+}
+
+// ToOutgoingMD returns the metadata of m as sent by clients, so that parsing it back gives m.
+// Empty fields are left out, and the device time is sent in RFC3339, to the second.
+// A parsed user-info is sent as received with its signature while UserInfo is unchanged,
+// otherwise it is sent unsigned unless signed WithSigningKeyring.
+func (m Metadata) ToOutgoingMD(opts ...BuildOption) metadata.MD {
+	b := &builder{}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	md := metadata.MD{}
+	set := func(key, value string) {
+		if value != "" {
+			md.Set(key, value)
+		}
+	}
+
+	set(MetadataTraceId, m.TraceId)
+	set(MetadataAcceptLang, string(m.DeviceLang))
+	if m.AppVersion != nil {
+		set(MetadataAppVersion, m.AppVersion.Original())
+	}
+	if m.OSVersion != nil {
+		set(MetadataOSVersion, m.OSVersion.Original())
+	}
+	if m.OperatingSystem != nil {
+		set(MetadataOperatingSystem, operatingSystemHeader(*m.OperatingSystem))
+	}
+	set(MetadataDeviceType, m.DeviceType)
+	set(MetadataDeviceUuid, m.DeviceUUID)
+	set(MetadataRequestId, m.RequestId)
+	set(MetadataToken, m.Token)
+	set(MetadataDeviceId, m.DeviceId)
+	set(MetadataManufacturer, m.Manufacturer)
+	set(MetadataDeviceModel, m.DeviceModel)
+	if m.DeviceTime != nil {
+		set(MetadataDeviceTime, m.DeviceTime.Format(time.RFC3339))
+	}
+	if m.UserInfo != nil {
+		if m.userInfoUnchanged() {
+			set(MetadataUserInfo, m.UserInfoRaw)
+			set(MetadataUserInfoSignature, m.UserInfoSignature)
+		} else if byt, err := json.Marshal(m.UserInfo); err == nil {
+			set(MetadataUserInfo, string(byt))
+		}
+		if b.keyring != nil {
+			if err := b.keyring.SignMD(md); err != nil {
+				log.Printf("[ToOutgoingMD] error when sign user-info. error: %s\n", err.Error())
+			}
+		}
+	}
+	if m.TraceContext != nil {
+		SetTraceContext(md, *m.TraceContext)
+	}
+	return md
+}
+
+// ToIncomingContext returns a copy of ctx as if m was received from a client, e.g. for
+// cron jobs, pubsub consumers and tests calling code that parses the incoming metadata.
+func (m Metadata) ToIncomingContext(ctx context.Context, opts ...BuildOption) context.Context {
+	ctx = metadata.NewIncomingContext(ctx, m.ToOutgoingMD(opts...))
+	if m.TraceId != "" {
+		ctx = context.WithValue(ctx, MetadataTraceId, m.TraceId)
+	}
+	return ctx
+}
+
+// ToHTTPHeader returns the metadata of m as HTTP headers.
+func (m Metadata) ToHTTPHeader(opts ...BuildOption) http.Header {
+	header := http.Header{}
+	for k, v := range m.ToOutgoingMD(opts...) {
+		for _, value := range v {
+			header.Add(k, value)
+		}
+	}
+	return header
+}
+
+// userInfoUnchanged reports whether UserInfo is still the user-info received as UserInfoRaw.
+func (m Metadata) userInfoUnchanged() bool {
+	if m.UserInfoRaw == "" {
+		return false
+	}
+	received := UserInfo{}
+	if err := json.Unmarshal([]byte(m.UserInfoRaw), &received); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(received, *m.UserInfo)
+}
+
+// operatingSystemHeader returns the value clients send for os, e.g. "android huawei".
+func operatingSystemHeader(os constant.OperatingSystem) string {
+	for header, v := range constant.DeviceTypeIndex {
+		if v == os {
+			return header
+		}
+	}
+	return os.String()
+}
@@ -0,0 +1,67 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/LukmanulHakim18/core/constant"
+	"github.com/LukmanulHakim18/core/feature"
+	"github.com/hashicorp/go-version"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestMetadata_roundTrip(t *testing.T) {
+	deviceTime := time.Date(2009, 11, 11, 6, 0, 0, 0, time.FixedZone("WIB", 7*60*60))
+	os := constant.OperatingSystemAndroidHuawei
+	m := Metadata{
+		TraceId:         "trace-1",
+		DeviceLang:      constant.DEVICE_LANG_ID,
+		AppVersion:      version.Must(version.NewSemver("6.2.1")),
+		OSVersion:       version.Must(version.NewSemver("11")),
+		DeviceType:      "Redmi",
+		DeviceUUID:      "691d84c7585eee4a",
+		RequestId:       "691d84-c7585-eee4a",
+		Token:           "token",
+		DeviceTime:      &deviceTime,
+		DeviceId:        "device-1",
+		Manufacturer:    "Xiaomi",
+		DeviceModel:     "Redmi Note 8 Pro",
+		OperatingSystem: &os,
+		UserInfo:        &UserInfo{InternalID: "BB12345", Name: "Budi", EnabledFeature: feature.EnabledFeature{}},
+	}
+
+	got, err := NewParser().Parse(m.ToIncomingContext(context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.DeviceTime == nil || !got.DeviceTime.Equal(deviceTime) {
+		t.Errorf("DeviceTime = %v, want %v", got.DeviceTime, deviceTime)
+	}
+	got.DeviceTime, m.DeviceTime = nil, nil
+	if got.UserInfoRaw == "" {
+		t.Errorf("UserInfoRaw is empty, want the received user-info")
+	}
+	got.UserInfoRaw = ""
+	if !reflect.DeepEqual(got, m) {
+		t.Errorf("round trip = %+v, want %+v", got, m)
+	}
+
+	header := m.ToHTTPHeader()
+	if header.Get("Operating-System") != "android huawei" || header.Get("Accept-Language") != "ID" {
+		t.Errorf("ToHTTPHeader() = %v", header)
+	}
+	md := metadata.MD{}
+	for k, v := range header {
+		md.Append(k, v...)
+	}
+	if !reflect.DeepEqual(md, m.ToOutgoingMD()) {
+		t.Errorf("ToHTTPHeader() = %v, want the same keys as ToOutgoingMD() %v", md, m.ToOutgoingMD())
+	}
+
+	if empty := (Metadata{}).ToHTTPHeader(); !reflect.DeepEqual(empty, http.Header{}) {
+		t.Errorf("ToHTTPHeader() of empty Metadata = %v, want no header", empty)
+	}
+}
@@ -0,0 +1,65 @@
+package metadata
+
+import (
+	"context"
+	"log"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+)
+
+type metadataContextKey struct{}
+
+// InitiateMetadata parses the incoming metadata once and stores the result in the
+// returned context, after making sure the request has a trace-id. Later calls to
+// GetMetaDataFromContext and FromContext return the stored Metadata without parsing,
+// so every log line of the request uses the same trace-id.
+func InitiateMetadata(ctx context.Context) context.Context {
+	ctx, err := InitiateMetadataWithError(ctx)
+	if err != nil {
+		log.Printf("[InitiateMetadata] %s\n", err.Error())
+	}
+	return ctx
+}
+
+// InitiateMetadataWithError is InitiateMetadata returning the parse error, a *ParseError,
+// instead of logging it, e.g. to fail a request whose user-info is rejected. The error
+// is nil when ctx already holds the Metadata.
+func InitiateMetadataWithError(ctx context.Context) (context.Context, error) {
+	if _, ok := FromContext(ctx); ok {
+		return ctx, nil
+	}
+	ctx = InitiateTraceId(ctx)
+	m, err := defaultParser.Parse(ctx)
+	return WithMetadata(ctx, m), err
+}
+
+// WithMetadata returns a copy of ctx holding m. The stored Metadata is meant to be
+// read only, it is shared by everything handling the request.
+func WithMetadata(ctx context.Context, m Metadata) context.Context {
+	return context.WithValue(ctx, metadataContextKey{}, m)
+}
+
+// FromContext returns the Metadata stored by InitiateMetadata or WithMetadata.
+func FromContext(ctx context.Context) (Metadata, bool) {
+	m, ok := ctx.Value(metadataContextKey{}).(Metadata)
+	return m, ok
+}
+
+// TraceIdFromContext returns the trace-id of the request: the incoming trace-id, the one
+// stored by InitiateTraceId, else the trace id of the active APM span or transaction or of
+// the traceparent or B3 headers. Without any of these it generates a new one each call, so
+// call InitiateTraceId once per request for calls fanned out from it to share the trace-id.
+func TraceIdFromContext(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if tmp := md.Get(MetadataTraceId); len(tmp) > 0 && tmp[0] != "" {
+		return tmp[0]
+	}
+	if traceId, ok := ctx.Value(MetadataTraceId).(string); ok && traceId != "" {
+		return traceId
+	}
+	if tc, ok := TraceContextFromContext(ctx); ok {
+		return tc.Trace.String()
+	}
+	return uuid.NewString()
+}
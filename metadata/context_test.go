@@ -0,0 +1,68 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LukmanulHakim18/core/constant"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestInitiateMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		wantTraceId string
+	}{
+		{
+			name:        "trace-id from client",
+			ctx:         metadata.NewIncomingContext(context.Background(), metadata.MD{MetadataTraceId: {"trace-1"}, MetadataAcceptLang: {"id"}}),
+			wantTraceId: "trace-1",
+		},
+		{
+			name: "generated trace-id",
+			ctx:  metadata.NewIncomingContext(context.Background(), metadata.MD{MetadataAcceptLang: {"id"}}),
+		},
+		{
+			name: "without incoming metadata",
+			ctx:  context.Background(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := InitiateMetadata(tt.ctx)
+
+			m, ok := FromContext(ctx)
+			if !ok || m.TraceId == "" {
+				t.Fatalf("FromContext() = %+v, %v, want parsed metadata with a trace-id", m, ok)
+			}
+			if tt.wantTraceId != "" && m.TraceId != tt.wantTraceId {
+				t.Errorf("Metadata.TraceId = %v, want %v", m.TraceId, tt.wantTraceId)
+			}
+
+			traceId, _ := GetTraceIdFromCtx(ctx)
+			for _, got := range []string{
+				GetMetaDataFromContext(ctx).TraceId,
+				GetMetaDataFromContextWithDeviceTimeQueryUnescape(ctx).TraceId,
+				GetMetaDataFromContext(InitiateMetadata(ctx)).TraceId,
+				ctx.Value(MetadataTraceId).(string),
+				traceId,
+			} {
+				if got != m.TraceId {
+					t.Errorf("trace-id = %v, want %v on every call", got, m.TraceId)
+				}
+			}
+		})
+	}
+}
+
+func TestGetMetaDataFromContext_traceIdWithoutInitiate(t *testing.T) {
+	ctx := InitiateTraceId(metadata.NewIncomingContext(context.Background(), metadata.MD{}))
+	first, second := GetMetaDataFromContext(ctx), GetMetaDataFromContext(ctx)
+	if first.TraceId != second.TraceId {
+		t.Errorf("GetMetaDataFromContext() trace-id = %v then %v, want the same", first.TraceId, second.TraceId)
+	}
+	if lang := GetDeviceLanguageFromCtx(WithMetadata(ctx, Metadata{DeviceLang: constant.DEVICE_LANG_ID})); lang != constant.DEVICE_LANG_ID {
+		t.Errorf("GetDeviceLanguageFromCtx() = %v, want the stored language", lang)
+	}
+}
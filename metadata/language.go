@@ -0,0 +1,49 @@
+package metadata
+
+import (
+	"sync"
+
+	"github.com/LukmanulHakim18/core/constant"
+	"golang.org/x/text/language"
+)
+
+var (
+	supportedLanguagesMu sync.RWMutex
+	supportedLanguages   = []constant.DeviceLang{constant.DEVICE_LANG_EN, constant.DEVICE_LANG_ID}
+)
+
+// SetSupportedLanguages sets the languages negotiated from the accept-language,
+// the first one being the default. English and Indonesian by default.
+func SetSupportedLanguages(langs ...constant.DeviceLang) {
+	if len(langs) == 0 {
+		return
+	}
+	supportedLanguagesMu.Lock()
+	defer supportedLanguagesMu.Unlock()
+	supportedLanguages = append([]constant.DeviceLang(nil), langs...)
+}
+
+// SupportedLanguages returns the languages set with SetSupportedLanguages.
+func SupportedLanguages() []constant.DeviceLang {
+	supportedLanguagesMu.RLock()
+	defer supportedLanguagesMu.RUnlock()
+	return append([]constant.DeviceLang(nil), supportedLanguages...)
+}
+
+// NegotiateLanguage returns the supported language preferred by an accept-language
+// value, e.g. "id-ID,id;q=0.9,en;q=0.8". Ranges are tried by quality value and each
+// is matched on its language as in RFC 4647 lookup, so "en-US" matches English.
+// The default language is returned when nothing matches.
+func NegotiateLanguage(acceptLanguage string) constant.DeviceLang {
+	supported := SupportedLanguages()
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	for _, tag := range tags {
+		lang := constant.DeviceLangFromTag(tag)
+		for _, s := range supported {
+			if lang.Is(s) {
+				return s
+			}
+		}
+	}
+	return supported[0]
+}
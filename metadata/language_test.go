@@ -0,0 +1,56 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LukmanulHakim18/core/constant"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestNegotiateLanguage(t *testing.T) {
+	tests := []struct {
+		name           string
+		supported      []constant.DeviceLang
+		acceptLanguage string
+		want           constant.DeviceLang
+	}{
+		{name: "upper-cased language", acceptLanguage: "ID", want: constant.DEVICE_LANG_ID},
+		{name: "region and quality values", acceptLanguage: "id-ID,id;q=0.9,en;q=0.8", want: constant.DEVICE_LANG_ID},
+		{name: "english region", acceptLanguage: "en-US", want: constant.DEVICE_LANG_EN},
+		{name: "highest quality first", acceptLanguage: "en;q=0.5, id;q=0.8", want: constant.DEVICE_LANG_ID},
+		{name: "zero quality ignored", acceptLanguage: "id;q=0, en;q=0.1", want: constant.DEVICE_LANG_EN},
+		{name: "unsupported falls to next range", acceptLanguage: "fr-FR, id;q=0.5", want: constant.DEVICE_LANG_ID},
+		{name: "unsupported language", acceptLanguage: "ms-MY", want: constant.DEVICE_LANG_EN},
+		{name: "empty", acceptLanguage: "", want: constant.DEVICE_LANG_EN},
+		{name: "invalid", acceptLanguage: "!!", want: constant.DEVICE_LANG_EN},
+		{
+			name:           "configured languages",
+			supported:      []constant.DeviceLang{constant.DEVICE_LANG_ID, constant.DEVICE_LANG_EN, constant.DEVICE_LANG_MS},
+			acceptLanguage: "ms-MY, en;q=0.9",
+			want:           constant.DEVICE_LANG_MS,
+		},
+		{
+			name:           "configured default",
+			supported:      []constant.DeviceLang{constant.DEVICE_LANG_ID, constant.DEVICE_LANG_EN},
+			acceptLanguage: "ja",
+			want:           constant.DEVICE_LANG_ID,
+		},
+	}
+	defer SetSupportedLanguages(constant.DEVICE_LANG_EN, constant.DEVICE_LANG_ID)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetSupportedLanguages(constant.DEVICE_LANG_EN, constant.DEVICE_LANG_ID)
+			if tt.supported != nil {
+				SetSupportedLanguages(tt.supported...)
+			}
+			if got := NegotiateLanguage(tt.acceptLanguage); got != tt.want {
+				t.Errorf("NegotiateLanguage(%q) = %v, want %v", tt.acceptLanguage, got, tt.want)
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{MetadataAcceptLang: {tt.acceptLanguage}})
+			if got := GetDeviceLanguageFromCtx(ctx); got != tt.want {
+				t.Errorf("GetDeviceLanguageFromCtx() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,17 +2,15 @@ package metadata
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
-	"net/url"
 	"strings"
 	"time"
 
 	"github.com/LukmanulHakim18/core/constant"
 	"github.com/LukmanulHakim18/core/feature"
-	"github.com/google/uuid"
 	"github.com/hashicorp/go-version"
+	"go.elastic.co/apm/v2"
 	"golang.org/x/exp/slices"
 	"google.golang.org/grpc/metadata"
 )
@@ -32,6 +30,8 @@ const (
 	MetadataRequestId       = "request-id"       // Ex: 691d84-c7585-eee4a
 	MetadataDeviceTime      = "device-time"      // Ex: 2009-11-11T06:00:00+07:00 if wib  using rfc3339
 	MetadataTraceId         = "trace-id"         // Ex: uuid, auto generate if nil from client
+
+	MetadataUserInfoSignature = "user-info-signature" // Ex: key-2024.<issued at unix>.<base64url signature of user-info and request-id>
 )
 
 var ListOfMetadataKey []string = []string{
@@ -49,6 +49,14 @@ var ListOfMetadataKey []string = []string{
 	MetadataRequestId,
 	MetadataDeviceTime,
 	MetadataTraceId,
+	MetadataUserInfoSignature,
+	MetadataTraceparent,
+	MetadataTracestate,
+	MetadataB3,
+	MetadataB3TraceId,
+	MetadataB3SpanId,
+	MetadataB3Sampled,
+	MetadataB3Flags,
 }
 
 func AllowCommonMetadata(key string) bool {
@@ -71,6 +79,12 @@ type Metadata struct {
 	DeviceModel     string
 	OperatingSystem *constant.OperatingSystem
 	UserInfo        *UserInfo
+	TraceContext    *apm.TraceContext
+
+	// UserInfoRaw and UserInfoSignature are the user-info as received and its signature,
+	// sent again unchanged while UserInfo is not modified so the next hop can verify them
+	UserInfoRaw       string
+	UserInfoSignature string
 }
 
 type UserInfo struct {
@@ -88,294 +102,38 @@ type UserInfo struct {
 
 // Deprecated: As of Aphrodite 1.7.1, this function simply calls [metadata.GetMetaDataFromContext].
 func MakeMetadataFromCtx(ctx context.Context) (meta Metadata, err error) {
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
+	if _, ok := metadata.FromIncomingContext(ctx); !ok {
 		err = fmt.Errorf("Oops! Something went wrong!")
 		return
 	}
-	meta.setDeviceLanguage(md)
-
-	if err = meta.setAppVersion(md); err != nil {
-		return
-	}
-	if err = meta.setDeviceType(md); err != nil {
-		return
-	}
-
-	if err = meta.setDeviceUUID(md); err != nil {
-		return
-	}
-
-	if err = meta.setRequestId(md); err != nil {
-		return
-	}
-
-	if err = meta.setToken(md); err != nil {
-		return
-	}
-	return
+	return NewParser(WithRequiredKeys(legacyRequiredMetadataKeys...)).Parse(ctx)
 }
 
+// GetMetaDataFromContext returns the Metadata stored by InitiateMetadata, or parses the
+// incoming metadata when there is none. Invalid keys are left empty, use a Parser
+// to get them reported.
 func GetMetaDataFromContext(ctx context.Context) Metadata {
-	var (
-		res = Metadata{}
-		tmp []string
-	)
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return res
-	}
-
-	// Accept-language
-	langArr := md.Get(MetadataAcceptLang)
-	if langArr != nil {
-		lang := strings.ToUpper(langArr[0])
-		if lang == "ID" {
-			res.DeviceLang = constant.DEVICE_LANG_ID
-		} else {
-			res.DeviceLang = constant.DEVICE_LANG_EN
-		}
-	} else {
-		res.DeviceLang = constant.DEVICE_LANG_EN // Default language
-	}
-
-	// App-Version
-	tmp = md.Get(MetadataAppVersion)
-	if len(tmp) > 0 {
-		res.AppVersion, _ = version.NewSemver(tmp[0])
-	}
-
-	// Token
-	tmp = md.Get(MetadataToken)
-	if len(tmp) > 0 {
-		res.Token = tmp[0]
-	}
-
-	// Device-Type
-	tmp = md.Get(MetadataDeviceType)
-	if len(tmp) > 0 {
-		res.DeviceType = tmp[0]
-	}
-
-	// Device-Uuid
-	tmp = md.Get(MetadataDeviceUuid)
-	if len(tmp) > 0 {
-		res.DeviceUUID = tmp[0]
-	}
-
-	// Os-Version
-	tmp = md.Get(MetadataOSVersion)
-	if len(tmp) > 0 {
-		res.OSVersion, _ = version.NewSemver(tmp[0])
-	}
-
-	// Operating-System
-	tmp = md.Get(MetadataOperatingSystem)
-	if len(tmp) > 0 {
-		res.OperatingSystem, _ = constant.OSFromStr(tmp[0])
-	}
-
-	// Device-Model
-	tmp = md.Get(MetadataDeviceModel)
-	if len(tmp) > 0 {
-		res.DeviceModel = tmp[0]
-	}
-
-	// Manufacturer
-	tmp = md.Get(MetadataManufacturer)
-	if len(tmp) > 0 {
-		res.Manufacturer = tmp[0]
-	}
-
-	// Device-Id
-	tmp = md.Get(MetadataDeviceId)
-	if len(tmp) > 0 {
-		res.DeviceId = tmp[0]
-	}
-
-	// Request-Id
-	tmp = md.Get(MetadataRequestId)
-	if len(tmp) > 0 {
-		res.RequestId = tmp[0]
-	}
-
-	// Device-Time
-	tmp = md.Get(MetadataDeviceTime)
-	if len(tmp) > 0 {
-		deviceTime := tmp[0]
-		layout := time.RFC3339
-		parsedTime, err := time.Parse(layout, deviceTime)
-		if err != nil {
-			log.Printf("[GetMetaDataFromContext] error when parse device-time string to time.Time. error: %s device-time: %s\n", err.Error(), deviceTime)
-			parsedTime = time.Now()
-		}
-		res.DeviceTime = &parsedTime
-
-	} else {
-		layout := time.RFC3339
-		now := time.Now().Format(layout)
-		parsedTime, err := time.Parse(layout, now)
-		if err == nil {
-			res.DeviceTime = &parsedTime
-		}
-	}
-
-	// User-Info
-	tmp = md.Get(MetadataUserInfo)
-	if len(tmp) > 0 {
-		userInfo := UserInfo{}
-		err := json.Unmarshal([]byte(tmp[0]), &userInfo)
-		if err == nil {
-			res.UserInfo = &userInfo
-		}
+	if m, ok := FromContext(ctx); ok {
+		return m
 	}
+	return parseMetadata(ctx)
+}
 
-	// Trace-Id
-	tmp = md.Get(MetadataTraceId)
-	if len(tmp) > 0 {
-		res.TraceId = tmp[0]
-	} else {
-		res.TraceId = uuid.NewString()
+func parseMetadata(ctx context.Context) Metadata {
+	res, err := defaultParser.Parse(ctx)
+	if err != nil {
+		log.Printf("[GetMetaDataFromContext] %s\n", err.Error())
 	}
-
 	return res
 }
 
+// GetMetaDataFromContextWithDeviceTimeQueryUnescape is GetMetaDataFromContext for
+// clients sending the device-time URL-encoded.
 func GetMetaDataFromContextWithDeviceTimeQueryUnescape(ctx context.Context) Metadata {
-	var (
-		res = Metadata{}
-		tmp []string
-	)
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return res
-	}
-
-	// Accept-language
-	langArr := md.Get(MetadataAcceptLang)
-	if langArr != nil {
-		lang := strings.ToUpper(langArr[0])
-		if lang == "ID" {
-			res.DeviceLang = constant.DEVICE_LANG_ID
-		} else {
-			res.DeviceLang = constant.DEVICE_LANG_EN
-		}
-	} else {
-		res.DeviceLang = constant.DEVICE_LANG_EN // Default language
-	}
-
-	// App-Version
-	tmp = md.Get(MetadataAppVersion)
-	if len(tmp) > 0 {
-		res.AppVersion, _ = version.NewSemver(tmp[0])
-	}
-
-	// Token
-	tmp = md.Get(MetadataToken)
-	if len(tmp) > 0 {
-		res.Token = tmp[0]
-	}
-
-	// Device-Type
-	tmp = md.Get(MetadataDeviceType)
-	if len(tmp) > 0 {
-		res.DeviceType = tmp[0]
-	}
-
-	// Device-Uuid
-	tmp = md.Get(MetadataDeviceUuid)
-	if len(tmp) > 0 {
-		res.DeviceUUID = tmp[0]
-	}
-
-	// Os-Version
-	tmp = md.Get(MetadataOSVersion)
-	if len(tmp) > 0 {
-		res.OSVersion, _ = version.NewSemver(tmp[0])
-	}
-
-	// Operating-System
-	tmp = md.Get(MetadataOperatingSystem)
-	if len(tmp) > 0 {
-		res.OperatingSystem, _ = constant.OSFromStr(tmp[0])
-	}
-
-	// Device-Model
-	tmp = md.Get(MetadataDeviceModel)
-	if len(tmp) > 0 {
-		res.DeviceModel = tmp[0]
-	}
-
-	// Manufacturer
-	tmp = md.Get(MetadataManufacturer)
-	if len(tmp) > 0 {
-		res.Manufacturer = tmp[0]
-	}
-
-	// Device-Id
-	tmp = md.Get(MetadataDeviceId)
-	if len(tmp) > 0 {
-		res.DeviceId = tmp[0]
-	}
-
-	// Request-Id
-	tmp = md.Get(MetadataRequestId)
-	if len(tmp) > 0 {
-		res.RequestId = tmp[0]
-	}
-
-	// Device-Time
-	tmp = md.Get(MetadataDeviceTime)
-	if len(tmp) > 0 {
-		deviceTime := tmp[0]
-
-		// URL-decode the time string first
-		deviceTimeAfterQueryUnescape, errQueryUnescape := url.QueryUnescape(deviceTime)
-		if errQueryUnescape != nil {
-			log.Printf("[GetMetaDataFromContextWithDeviceTimeQueryUnescape] error when url.QueryUnescape. error: %s device-time: %s\n", errQueryUnescape.Error(), deviceTime)
-		} else {
-			deviceTime = deviceTimeAfterQueryUnescape
-		}
-
-		layout := time.RFC3339
-		parsedTime, err := time.Parse(layout, deviceTime)
-		if err == nil {
-			res.DeviceTime = &parsedTime
-		} else {
-			log.Printf("[GetMetaDataFromContextWithDeviceTimeQueryUnescape] error when parse device-time string to time.Time. error: %s device-time: %s\n", err.Error(), deviceTime)
-			now := time.Now().Format(layout)
-			parsedTime, err := time.Parse(layout, now)
-			if err == nil {
-				res.DeviceTime = &parsedTime
-			}
-		}
-	} else {
-		layout := time.RFC3339
-		now := time.Now().Format(layout)
-		parsedTime, err := time.Parse(layout, now)
-		if err == nil {
-			res.DeviceTime = &parsedTime
-		}
-	}
-
-	// User-Info
-	tmp = md.Get(MetadataUserInfo)
-	if len(tmp) > 0 {
-		userInfo := UserInfo{}
-		err := json.Unmarshal([]byte(tmp[0]), &userInfo)
-		if err == nil {
-			res.UserInfo = &userInfo
-		}
-	}
-
-	// Trace-Id
-	tmp = md.Get(MetadataTraceId)
-	if len(tmp) > 0 {
-		res.TraceId = tmp[0]
-	} else {
-		res.TraceId = uuid.NewString()
+	res, err := queryUnescapeParser.Parse(ctx)
+	if err != nil {
+		log.Printf("[GetMetaDataFromContextWithDeviceTimeQueryUnescape] %s\n", err.Error())
 	}
-
 	return res
 }
 
@@ -386,91 +144,13 @@ func CopyMetaDataToNewContext(newCtx context.Context, oldCtx context.Context) co
 	return oldCtx
 }
 
-func (m *Metadata) setDeviceLanguage(md metadata.MD) {
-	// Get language requested from metadata(header in http), default is english
-	langArr := md.Get(MetadataAcceptLang)
-	if langArr != nil {
-		lang := strings.ToUpper(langArr[0])
-		if lang == "ID" {
-			m.DeviceLang = constant.DEVICE_LANG_ID
-		} else {
-			m.DeviceLang = constant.DEVICE_LANG_EN
-		}
-	} else {
-		m.DeviceLang = constant.DEVICE_LANG_EN // Default language
-	}
-}
-
-func (m *Metadata) setAppVersion(md metadata.MD) (err error) {
-
-	// Get language requested, default is english
-	appVersionSlice := md.Get(MetadataAppVersion)
-	if appVersionSlice == nil {
-		return fmt.Errorf("undefined version")
-	}
-	if m.AppVersion, err = version.NewSemver(appVersionSlice[0]); err != nil {
-		return err
-	}
-	return nil
-}
-
-func (m *Metadata) setDeviceType(md metadata.MD) (err error) {
-
-	// Get language requested, default is english
-	deviceType := md.Get(MetadataDeviceType)
-	if deviceType == nil {
-		return fmt.Errorf("undefined device type")
-	}
-	m.DeviceType = deviceType[0]
-	return
-}
-
-func (m *Metadata) setDeviceUUID(md metadata.MD) (err error) {
-	// Get language requested, default is english
-	deviceUuid := md.Get(MetadataDeviceUuid)
-	if deviceUuid == nil {
-		return fmt.Errorf("undefined device uuid")
-	}
-	m.DeviceUUID = deviceUuid[0]
-	return
-}
-
-func (m *Metadata) setRequestId(md metadata.MD) (err error) {
-	// Get language requested, default is english
-	requestId := md.Get(MetadataRequestId)
-	if requestId == nil {
-		return fmt.Errorf("undefined request id")
-	}
-	m.RequestId = requestId[0]
-	return
-}
-
-func (m *Metadata) setToken(md metadata.MD) error {
-	token := md.Get(MetadataToken)
-	if token == nil {
-		return fmt.Errorf("undefined token")
-	}
-	m.Token = token[0]
-	return nil
-}
-
 func GetDeviceLanguageFromCtx(ctx context.Context) constant.DeviceLang {
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return constant.DEVICE_LANG_EN
+	if m, ok := FromContext(ctx); ok {
+		return m.DeviceLang
 	}
 	// Get language requested from metadata(header in http), default is english
-	langArr := md.Get(MetadataAcceptLang)
-	if langArr != nil {
-		lang := strings.ToUpper(langArr[0])
-		if lang == "ID" {
-			return constant.DEVICE_LANG_ID
-		} else {
-			return constant.DEVICE_LANG_EN
-		}
-	} else {
-		return constant.DEVICE_LANG_EN // Default language
-	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	return NegotiateLanguage(strings.Join(md.Get(MetadataAcceptLang), ","))
 }
 
 func GetDeviceVersionFromCtx(ctx context.Context) (ver *version.Version, err error) {
@@ -547,6 +227,9 @@ func GetTokenFromCtx(ctx context.Context) (string, error) {
 
 // Get trace-id from context
 func GetTraceIdFromCtx(ctx context.Context) (string, error) {
+	if traceId, ok := ctx.Value(MetadataTraceId).(string); ok && traceId != "" {
+		return traceId, nil
+	}
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		return "", fmt.Errorf("trace-id not found")
@@ -579,9 +262,9 @@ func InitiateTraceId(ctx context.Context) context.Context {
 	}
 
 	traceId := md.Get(MetadataTraceId)
-	if len(traceId) == 0 {
-		newTraceId := uuid.NewString()
-		md.Set(MetadataTraceId, newTraceId)
+	if len(traceId) == 0 || traceId[0] == "" {
+		md = md.Copy()
+		md.Set(MetadataTraceId, TraceIdFromContext(ctx))
 		ctx = metadata.NewIncomingContext(ctx, md)
 	}
 
@@ -0,0 +1,216 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/LukmanulHakim18/core/constant"
+	"github.com/hashicorp/go-version"
+	"google.golang.org/grpc/metadata"
+)
+
+// DeviceTimeFallback decides the DeviceTime when the device-time is missing or invalid.
+type DeviceTimeFallback int
+
+const (
+	// DeviceTimeNow uses the current time, truncated to the second
+	DeviceTimeNow DeviceTimeFallback = iota
+	// DeviceTimeNil leaves DeviceTime nil
+	DeviceTimeNil
+)
+
+// ParseError lists the metadata keys that are required but missing, and the ones
+// present but invalid, e.g. an app-version that is not a semver.
+type ParseError struct {
+	Missing []string
+	Invalid []string
+}
+
+func (e *ParseError) Error() string {
+	problems := []string{}
+	if len(e.Missing) > 0 {
+		problems = append(problems, "missing metadata: "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Invalid) > 0 {
+		problems = append(problems, "invalid metadata: "+strings.Join(e.Invalid, ", "))
+	}
+	return strings.Join(problems, "; ")
+}
+
+// Parser parses the incoming metadata of a request into Metadata.
+type Parser struct {
+	required           []string
+	queryUnescape      bool
+	deviceTimeFallback DeviceTimeFallback
+	userInfoVerifier   *userInfoVerifier
+}
+
+// ParserOption configures a Parser.
+type ParserOption func(p *Parser)
+
+// WithRequiredKeys reports the keys as missing when they are absent or empty.
+func WithRequiredKeys(keys ...string) ParserOption {
+	return func(p *Parser) {
+		for _, k := range keys {
+			p.required = append(p.required, strings.ToLower(k))
+		}
+	}
+}
+
+// WithQueryUnescape URL-unescapes the device-time before parsing it,
+// for clients sending it URL-encoded.
+func WithQueryUnescape() ParserOption {
+	return func(p *Parser) {
+		p.queryUnescape = true
+	}
+}
+
+// WithDeviceTimeFallback sets the DeviceTime used when the device-time is missing
+// or invalid, DeviceTimeNow by default.
+func WithDeviceTimeFallback(fallback DeviceTimeFallback) ParserOption {
+	return func(p *Parser) {
+		p.deviceTimeFallback = fallback
+	}
+}
+
+// NewParser creates a new Parser instance.
+func NewParser(opts ...ParserOption) *Parser {
+	p := &Parser{}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Parse parses the incoming metadata of ctx. The Metadata holds every valid key even
+// when the returned error, a *ParseError, reports missing or invalid keys.
+func (p *Parser) Parse(ctx context.Context) (Metadata, error) {
+	var (
+		res    = Metadata{}
+		errs   = &ParseError{}
+		md, ok = metadata.FromIncomingContext(ctx)
+	)
+	for _, k := range p.required {
+		if tmp := md.Get(k); len(tmp) == 0 || tmp[0] == "" {
+			errs.Missing = append(errs.Missing, k)
+		}
+	}
+	if !ok {
+		return res, errs.orNil()
+	}
+
+	get := func(key string) (string, bool) {
+		tmp := md.Get(key)
+		if len(tmp) == 0 || tmp[0] == "" {
+			return "", false
+		}
+		return tmp[0], true
+	}
+
+	// Accept-language
+	res.DeviceLang = NegotiateLanguage(strings.Join(md.Get(MetadataAcceptLang), ","))
+
+	// App-Version
+	if v, ok := get(MetadataAppVersion); ok {
+		var err error
+		if res.AppVersion, err = version.NewSemver(v); err != nil {
+			errs.Invalid = append(errs.Invalid, MetadataAppVersion)
+		}
+	}
+
+	// Os-Version
+	if v, ok := get(MetadataOSVersion); ok {
+		var err error
+		if res.OSVersion, err = version.NewSemver(v); err != nil {
+			errs.Invalid = append(errs.Invalid, MetadataOSVersion)
+		}
+	}
+
+	// Operating-System
+	if v, ok := get(MetadataOperatingSystem); ok {
+		var err error
+		if res.OperatingSystem, err = constant.OSFromStr(v); err != nil {
+			errs.Invalid = append(errs.Invalid, MetadataOperatingSystem)
+		}
+	}
+
+	res.Token, _ = get(MetadataToken)
+	res.DeviceType, _ = get(MetadataDeviceType)
+	res.DeviceUUID, _ = get(MetadataDeviceUuid)
+	res.DeviceModel, _ = get(MetadataDeviceModel)
+	res.Manufacturer, _ = get(MetadataManufacturer)
+	res.DeviceId, _ = get(MetadataDeviceId)
+	res.RequestId, _ = get(MetadataRequestId)
+
+	// Device-Time
+	if v, ok := get(MetadataDeviceTime); ok {
+		if p.queryUnescape {
+			if unescaped, err := url.QueryUnescape(v); err == nil {
+				v = unescaped
+			}
+		}
+		if parsedTime, err := time.Parse(time.RFC3339, v); err == nil {
+			res.DeviceTime = &parsedTime
+		} else {
+			errs.Invalid = append(errs.Invalid, MetadataDeviceTime)
+		}
+	}
+	if res.DeviceTime == nil && p.deviceTimeFallback == DeviceTimeNow {
+		now := time.Now().Truncate(time.Second)
+		res.DeviceTime = &now
+	}
+
+	// User-Info, only when it passes the verification of its signature
+	if v, ok := get(MetadataUserInfo); ok {
+		trusted, rejected := p.verifier().verify(md, v)
+		if rejected {
+			errs.Invalid = append(errs.Invalid, MetadataUserInfoSignature)
+		}
+		if trusted {
+			userInfo := UserInfo{}
+			if err := json.Unmarshal([]byte(v), &userInfo); err == nil {
+				res.UserInfo = &userInfo
+				res.UserInfoRaw = v
+				res.UserInfoSignature, _ = get(MetadataUserInfoSignature)
+			} else {
+				errs.Invalid = append(errs.Invalid, MetadataUserInfo)
+			}
+		}
+	}
+
+	// Traceparent, tracestate or B3
+	if tc, ok := TraceContextFromMD(md); ok {
+		res.TraceContext = &tc
+	}
+
+	// Trace-Id
+	res.TraceId = TraceIdFromContext(ctx)
+
+	return res, errs.orNil()
+}
+
+// UserInfoRejected reports whether the user-info failed verification with UserInfoReject.
+func (e *ParseError) UserInfoRejected() bool {
+	for _, k := range e.Invalid {
+		if k == MetadataUserInfoSignature {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *ParseError) orNil() error {
+	if len(e.Missing) == 0 && len(e.Invalid) == 0 {
+		return nil
+	}
+	return e
+}
+
+var (
+	defaultParser              = NewParser()
+	queryUnescapeParser        = NewParser(WithQueryUnescape())
+	legacyRequiredMetadataKeys = []string{MetadataAppVersion, MetadataDeviceType, MetadataDeviceUuid, MetadataRequestId, MetadataToken}
+)
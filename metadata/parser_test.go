@@ -0,0 +1,121 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestParser_Parse(t *testing.T) {
+	tests := []struct {
+		name           string
+		md             metadata.MD
+		opts           []ParserOption
+		wantErr        *ParseError
+		wantDeviceTime string
+		wantNilTime    bool
+	}{
+		{
+			name: "valid",
+			md: metadata.MD{
+				MetadataAppVersion: {"6.2.1"},
+				MetadataDeviceTime: {"2009-11-11T06:00:00+07:00"},
+				MetadataUserInfo:   {`{"internal_id":"BB12345"}`},
+			},
+			opts:           []ParserOption{WithRequiredKeys(MetadataAppVersion, "Device-Time")},
+			wantDeviceTime: "2009-11-11T06:00:00+07:00",
+		},
+		{
+			name: "missing and invalid keys",
+			md: metadata.MD{
+				MetadataAppVersion:      {"latest"},
+				MetadataOperatingSystem: {"symbian"},
+				MetadataDeviceTime:      {"yesterday"},
+				MetadataUserInfo:        {"BB12345"},
+				MetadataToken:           {""},
+			},
+			opts: []ParserOption{WithRequiredKeys(MetadataToken, MetadataRequestId)},
+			wantErr: &ParseError{
+				Missing: []string{MetadataToken, MetadataRequestId},
+				Invalid: []string{MetadataAppVersion, MetadataOperatingSystem, MetadataDeviceTime, MetadataUserInfo},
+			},
+		},
+		{
+			name:           "URL-encoded device-time",
+			md:             metadata.MD{MetadataDeviceTime: {"2009-11-11T06%3A00%3A00%2B07%3A00"}},
+			opts:           []ParserOption{WithQueryUnescape()},
+			wantDeviceTime: "2009-11-11T06:00:00+07:00",
+		},
+		{
+			name:    "URL-encoded device-time without unescaping",
+			md:      metadata.MD{MetadataDeviceTime: {"2009-11-11T06%3A00%3A00%2B07%3A00"}},
+			wantErr: &ParseError{Invalid: []string{MetadataDeviceTime}},
+		},
+		{
+			name:        "device-time without fallback",
+			md:          metadata.MD{},
+			opts:        []ParserOption{WithDeviceTimeFallback(DeviceTimeNil)},
+			wantNilTime: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			got, err := NewParser(tt.opts...).Parse(ctx)
+
+			var parseErr *ParseError
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Parser.Parse() error = %v, want nil", err)
+			}
+			if tt.wantErr != nil && (!errors.As(err, &parseErr) || !reflect.DeepEqual(parseErr, tt.wantErr)) {
+				t.Fatalf("Parser.Parse() error = %#v, want %#v", err, tt.wantErr)
+			}
+
+			switch {
+			case tt.wantNilTime && got.DeviceTime != nil:
+				t.Errorf("Metadata.DeviceTime = %v, want nil", got.DeviceTime)
+			case tt.wantDeviceTime != "" && (got.DeviceTime == nil || got.DeviceTime.Format(time.RFC3339) != tt.wantDeviceTime):
+				t.Errorf("Metadata.DeviceTime = %v, want %v", got.DeviceTime, tt.wantDeviceTime)
+			case !tt.wantNilTime && got.DeviceTime == nil:
+				t.Errorf("Metadata.DeviceTime = nil, want the current time as fallback")
+			}
+			if got.TraceId == "" {
+				t.Errorf("Metadata.TraceId is empty")
+			}
+		})
+	}
+}
+
+func TestMakeMetadataFromCtx(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{MetadataAppVersion: {"6.2.1"}})
+	_, err := MakeMetadataFromCtx(ctx)
+	want := &ParseError{Missing: []string{MetadataDeviceType, MetadataDeviceUuid, MetadataRequestId, MetadataToken}}
+	if !reflect.DeepEqual(err, want) {
+		t.Errorf("MakeMetadataFromCtx() error = %v, want %v", err, want)
+	}
+}
+
+func TestGetMetaDataFromContext_logsFailureOnly(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	GetMetaDataFromContext(metadata.NewIncomingContext(context.Background(), metadata.MD{MetadataAppVersion: {"6.2.1"}}))
+	GetMetaDataFromContext(context.Background())
+	if buf.Len() != 0 {
+		t.Errorf("GetMetaDataFromContext() logged %q for valid metadata", buf.String())
+	}
+
+	GetMetaDataFromContext(metadata.NewIncomingContext(context.Background(), metadata.MD{MetadataAppVersion: {"latest"}}))
+	if !strings.Contains(buf.String(), MetadataAppVersion) {
+		t.Errorf("GetMetaDataFromContext() log = %q, want the invalid app-version", buf.String())
+	}
+}
@@ -0,0 +1,297 @@
+package metadata
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+var (
+	ErrUserInfoUnsigned      = errors.New("user-info is not signed")
+	ErrUserInfoSignature     = errors.New("user-info signature is invalid")
+	ErrUserInfoUnknownKey    = errors.New("user-info is signed with an unknown key")
+	ErrUserInfoExpired       = errors.New("user-info signature is expired")
+	ErrSigningKeyVerifyOnly  = errors.New("signing key can only verify")
+	ErrSigningKeyNotFound    = errors.New("signing key not found")
+	errSigningKeyWithoutKeys = errors.New("keyring without signing key")
+)
+
+// SigningKey signs and verifies the user-info header. Keys are identified by ID so
+// services can verify with several keys while the gateway rotates to a new one.
+type SigningKey struct {
+	ID         string
+	hmacSecret []byte
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+}
+
+// NewHMACKey creates a new HMAC-SHA256 SigningKey, shared by the gateway and the services.
+func NewHMACKey(id string, secret []byte) *SigningKey {
+	return &SigningKey{ID: id, hmacSecret: secret}
+}
+
+// NewEd25519Key creates a new Ed25519 SigningKey for the gateway, signing with privateKey.
+func NewEd25519Key(id string, privateKey ed25519.PrivateKey) *SigningKey {
+	return &SigningKey{ID: id, privateKey: privateKey, publicKey: privateKey.Public().(ed25519.PublicKey)}
+}
+
+// NewEd25519VerifyKey creates a new Ed25519 SigningKey for the services, verifying only.
+func NewEd25519VerifyKey(id string, publicKey ed25519.PublicKey) *SigningKey {
+	return &SigningKey{ID: id, publicKey: publicKey}
+}
+
+func (k *SigningKey) sign(payload []byte) ([]byte, error) {
+	switch {
+	case k.hmacSecret != nil:
+		mac := hmac.New(sha256.New, k.hmacSecret)
+		mac.Write(payload)
+		return mac.Sum(nil), nil
+	case k.privateKey != nil:
+		return ed25519.Sign(k.privateKey, payload), nil
+	default:
+		return nil, ErrSigningKeyVerifyOnly
+	}
+}
+
+func (k *SigningKey) verify(payload, signature []byte) bool {
+	if k.hmacSecret != nil {
+		expected, _ := k.sign(payload)
+		return hmac.Equal(expected, signature)
+	}
+	return k.publicKey != nil && ed25519.Verify(k.publicKey, payload, signature)
+}
+
+const (
+	// DefaultSignatureMaxAge is how long a user-info signature is accepted after it is issued
+	DefaultSignatureMaxAge = 5 * time.Minute
+	// signatureClockSkew accepts signatures issued slightly in the future by another host
+	signatureClockSkew = time.Minute
+)
+
+// Keyring holds the keys used to sign and verify the user-info header.
+// To rotate, add the new key to every service, make it current on the gateway,
+// then remove the old key once no request signed with it is in flight.
+//
+// The signature covers the user-info, the request-id and the time it was issued, so a
+// captured user-info-signature only verifies with the same request-id, and only until
+// it is older than the max age. Within that window it can still be replayed.
+type Keyring struct {
+	mu      sync.RWMutex
+	keys    map[string]*SigningKey
+	current string
+	maxAge  time.Duration
+	now     func() time.Time
+}
+
+// NewKeyring creates a new Keyring signing with the first key.
+func NewKeyring(keys ...*SigningKey) *Keyring {
+	k := &Keyring{keys: map[string]*SigningKey{}, maxAge: DefaultSignatureMaxAge, now: time.Now}
+	for _, key := range keys {
+		k.Add(key)
+	}
+	if len(keys) > 0 {
+		k.current = keys[0].ID
+	}
+	return k
+}
+
+// Add adds a key to verify with, replacing the key with the same ID.
+func (k *Keyring) Add(key *SigningKey) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[key.ID] = key
+}
+
+// Remove removes a key, signatures made with it no longer verify.
+func (k *Keyring) Remove(id string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.keys, id)
+	if k.current == id {
+		k.current = ""
+	}
+}
+
+// SetCurrent sets the key used to sign.
+func (k *Keyring) SetCurrent(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.keys[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrSigningKeyNotFound, id)
+	}
+	k.current = id
+	return nil
+}
+
+// SetMaxAge sets how long a signature is accepted after it is issued,
+// DefaultSignatureMaxAge by default. Zero accepts signatures of any age.
+func (k *Keyring) SetMaxAge(maxAge time.Duration) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.maxAge = maxAge
+}
+
+// Sign returns the user-info-signature header value of userInfo, the exact user-info
+// header value, for the request requestId. The value is "keyID.issuedAt.signature".
+func (k *Keyring) Sign(userInfo []byte, requestId string) (string, error) {
+	k.mu.RLock()
+	key, ok := k.keys[k.current]
+	issuedAt := k.now().Unix()
+	k.mu.RUnlock()
+	if !ok {
+		return "", errSigningKeyWithoutKeys
+	}
+	signature, err := key.sign(signedPayload(userInfo, requestId, issuedAt))
+	if err != nil {
+		return "", err
+	}
+	return key.ID + "." + strconv.FormatInt(issuedAt, 10) + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Verify checks the user-info-signature header value of userInfo for the request requestId.
+func (k *Keyring) Verify(userInfo []byte, requestId, signature string) error {
+	if signature == "" {
+		return ErrUserInfoUnsigned
+	}
+	idx := strings.LastIndex(signature, ".")
+	if idx < 0 {
+		return ErrUserInfoSignature
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature[idx+1:])
+	if err != nil {
+		return ErrUserInfoSignature
+	}
+	keyId := signature[:idx]
+	idx = strings.LastIndex(keyId, ".")
+	if idx < 0 {
+		return ErrUserInfoSignature
+	}
+	issuedAt, err := strconv.ParseInt(keyId[idx+1:], 10, 64)
+	if err != nil {
+		return ErrUserInfoSignature
+	}
+	keyId = keyId[:idx]
+
+	k.mu.RLock()
+	key, ok := k.keys[keyId]
+	maxAge, now := k.maxAge, k.now()
+	k.mu.RUnlock()
+	if !ok {
+		return ErrUserInfoUnknownKey
+	}
+	if !key.verify(signedPayload(userInfo, requestId, issuedAt), sig) {
+		return ErrUserInfoSignature
+	}
+	issued := time.Unix(issuedAt, 0)
+	if issued.After(now.Add(signatureClockSkew)) || (maxAge > 0 && now.Sub(issued) > maxAge) {
+		return ErrUserInfoExpired
+	}
+	return nil
+}
+
+// signedPayload binds userInfo to the request and the signing time. The request-id and
+// the time cannot contain a newline, so the fields cannot be shifted into each other.
+func signedPayload(userInfo []byte, requestId string, issuedAt int64) []byte {
+	payload := strconv.FormatInt(issuedAt, 10) + "\n" + requestId + "\n"
+	return append([]byte(payload), userInfo...)
+}
+
+// SignMD sets the user-info-signature of the user-info and request-id of md, e.g. on
+// the gateway after building the outgoing metadata.
+func (k *Keyring) SignMD(md metadata.MD) error {
+	userInfo := md.Get(MetadataUserInfo)
+	if len(userInfo) == 0 {
+		md.Delete(MetadataUserInfoSignature)
+		return nil
+	}
+	signature, err := k.Sign([]byte(userInfo[0]), firstValue(md, MetadataRequestId))
+	if err != nil {
+		return err
+	}
+	md.Set(MetadataUserInfoSignature, signature)
+	return nil
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if tmp := md.Get(key); len(tmp) > 0 {
+		return tmp[0]
+	}
+	return ""
+}
+
+// UserInfoVerification is what parsing does with a user-info that fails verification.
+type UserInfoVerification int
+
+const (
+	// UserInfoTrusted trusts the user-info without verifying it
+	UserInfoTrusted UserInfoVerification = iota
+	// UserInfoReject reports the user-info-signature as invalid in the ParseError,
+	// the gRPC MetadataServerInterceptor then fails the request as unauthenticated
+	UserInfoReject
+	// UserInfoStrip logs the failure and parses the request without user-info
+	UserInfoStrip
+)
+
+type userInfoVerifier struct {
+	keyring *Keyring
+	mode    UserInfoVerification
+}
+
+var (
+	userInfoVerificationMu sync.RWMutex
+	userInfoVerification   = &userInfoVerifier{}
+)
+
+// SetUserInfoVerification sets how every Parser, GetMetaDataFromContext and
+// InitiateMetadata verify the user-info. Parsers built with WithUserInfoVerification
+// keep their own setting.
+func SetUserInfoVerification(keyring *Keyring, mode UserInfoVerification) {
+	userInfoVerificationMu.Lock()
+	defer userInfoVerificationMu.Unlock()
+	userInfoVerification = &userInfoVerifier{keyring: keyring, mode: mode}
+}
+
+// WithUserInfoVerification verifies the user-info with keyring, see UserInfoVerification.
+func WithUserInfoVerification(keyring *Keyring, mode UserInfoVerification) ParserOption {
+	return func(p *Parser) {
+		p.userInfoVerifier = &userInfoVerifier{keyring: keyring, mode: mode}
+	}
+}
+
+func (p *Parser) verifier() *userInfoVerifier {
+	if p.userInfoVerifier != nil {
+		return p.userInfoVerifier
+	}
+	userInfoVerificationMu.RLock()
+	defer userInfoVerificationMu.RUnlock()
+	return userInfoVerification
+}
+
+// verify reports whether the user-info may be used, and whether it is rejected.
+func (v *userInfoVerifier) verify(md metadata.MD, userInfo string) (trusted bool, rejected bool) {
+	if v.mode == UserInfoTrusted {
+		return true, false
+	}
+	err := ErrSigningKeyNotFound
+	if v.keyring != nil {
+		err = v.keyring.Verify([]byte(userInfo), firstValue(md, MetadataRequestId), firstValue(md, MetadataUserInfoSignature))
+	}
+	if err == nil {
+		return true, false
+	}
+	if v.mode == UserInfoReject {
+		return false, true
+	}
+	log.Printf("[metadata] strip user-info. error: %s\n", err.Error())
+	return false, false
+}
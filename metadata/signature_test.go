@@ -0,0 +1,150 @@
+package metadata
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestKeyring(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	payload := []byte(`{"internal_id":"BB12345"}`)
+
+	oldKey, newKey := NewHMACKey("2024", []byte("old secret")), NewHMACKey("2025", []byte("new secret"))
+	gateway := NewKeyring(oldKey, newKey)
+	service := NewKeyring(oldKey, newKey)
+
+	signedOld, _ := gateway.Sign(payload, "req-1")
+	if err := gateway.SetCurrent("2025"); err != nil {
+		t.Fatal(err)
+	}
+	signedNew, _ := gateway.Sign(payload, "req-1")
+	service.Remove("2024")
+
+	signedEd25519, _ := NewKeyring(NewEd25519Key("ed", private)).Sign(payload, "req-1")
+	if _, err := NewKeyring(NewEd25519VerifyKey("ed", public)).Sign(payload, "req-1"); !errors.Is(err, ErrSigningKeyVerifyOnly) {
+		t.Errorf("Sign() with a verify only key error = %v, want %v", err, ErrSigningKeyVerifyOnly)
+	}
+	service.Add(NewEd25519VerifyKey("ed", public))
+
+	gateway.now = func() time.Time { return time.Now().Add(-time.Hour) }
+	signedExpired, _ := gateway.Sign(payload, "req-1")
+	gateway.now = func() time.Time { return time.Now().Add(time.Hour) }
+	signedFuture, _ := gateway.Sign(payload, "req-1")
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		requestId string
+		signature string
+		want      error
+	}{
+		{name: "current key", payload: payload, requestId: "req-1", signature: signedNew},
+		{name: "Ed25519", payload: payload, requestId: "req-1", signature: signedEd25519},
+		{name: "rotated out key", payload: payload, requestId: "req-1", signature: signedOld, want: ErrUserInfoUnknownKey},
+		{name: "tampered payload", payload: []byte(`{"internal_id":"BB99999"}`), requestId: "req-1", signature: signedNew, want: ErrUserInfoSignature},
+		{name: "replayed on another request", payload: payload, requestId: "req-2", signature: signedNew, want: ErrUserInfoSignature},
+		{name: "older than max age", payload: payload, requestId: "req-1", signature: signedExpired, want: ErrUserInfoExpired},
+		{name: "issued in the future", payload: payload, requestId: "req-1", signature: signedFuture, want: ErrUserInfoExpired},
+		{name: "malformed signature", payload: payload, requestId: "req-1", signature: "2025.!!", want: ErrUserInfoSignature},
+		{name: "malformed issued at", payload: payload, requestId: "req-1", signature: "2025.x.AAAA", want: ErrUserInfoSignature},
+		{name: "unsigned", payload: payload, requestId: "req-1", want: ErrUserInfoUnsigned},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := service.Verify(tt.payload, tt.requestId, tt.signature); !errors.Is(err, tt.want) {
+				t.Errorf("Keyring.Verify() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_userInfoVerification(t *testing.T) {
+	keyring := NewKeyring(NewHMACKey("2025", []byte("secret")))
+	signed := metadata.MD{MetadataUserInfo: {`{"internal_id":"BB12345"}`}}
+	if err := keyring.SignMD(signed); err != nil {
+		t.Fatal(err)
+	}
+	spoofed := metadata.MD{
+		MetadataUserInfo:          {`{"internal_id":"BB99999"}`},
+		MetadataUserInfoSignature: signed.Get(MetadataUserInfoSignature),
+	}
+
+	tests := []struct {
+		name         string
+		md           metadata.MD
+		mode         UserInfoVerification
+		wantUserInfo bool
+		wantInvalid  []string
+	}{
+		{name: "trusted", md: spoofed, mode: UserInfoTrusted, wantUserInfo: true},
+		{name: "signed with reject", md: signed, mode: UserInfoReject, wantUserInfo: true},
+		{name: "spoofed with reject", md: spoofed, mode: UserInfoReject, wantInvalid: []string{MetadataUserInfoSignature}},
+		{name: "spoofed with strip", md: spoofed, mode: UserInfoStrip},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			got, err := NewParser(WithUserInfoVerification(keyring, tt.mode)).Parse(ctx)
+
+			if (got.UserInfo != nil) != tt.wantUserInfo {
+				t.Errorf("Metadata.UserInfo = %+v, want user-info %v", got.UserInfo, tt.wantUserInfo)
+			}
+			var parseErr *ParseError
+			if errors.As(err, &parseErr) != (tt.wantInvalid != nil) || (parseErr != nil && !reflect.DeepEqual(parseErr.Invalid, tt.wantInvalid)) {
+				t.Errorf("Parser.Parse() error = %v, want invalid %v", err, tt.wantInvalid)
+			}
+		})
+	}
+
+	SetUserInfoVerification(keyring, UserInfoStrip)
+	defer SetUserInfoVerification(nil, UserInfoTrusted)
+	if got := GetMetaDataFromContext(metadata.NewIncomingContext(context.Background(), spoofed)); got.UserInfo != nil {
+		t.Errorf("GetMetaDataFromContext() = %+v, want the spoofed user-info stripped", got.UserInfo)
+	}
+}
+
+func TestMetadata_signedRoundTrip(t *testing.T) {
+	keyring := NewKeyring(NewHMACKey("2025", []byte("secret")))
+	parser := NewParser(WithUserInfoVerification(keyring, UserInfoReject))
+	signed := metadata.MD{
+		MetadataRequestId: {"req-1"},
+		MetadataUserInfo:  {`{"internal_id":"BB12345", "name":"Budi"}`},
+	}
+	if err := keyring.SignMD(signed); err != nil {
+		t.Fatal(err)
+	}
+	m, err := parser.Parse(metadata.NewIncomingContext(context.Background(), signed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the next hop gets the user-info and signature as received
+	next, err := parser.Parse(m.ToIncomingContext(context.Background()))
+	if err != nil || next.UserInfo == nil || next.UserInfoRaw != signed.Get(MetadataUserInfo)[0] {
+		t.Fatalf("Parse() of the rebuilt metadata = %+v, %v, want the signed user-info", next.UserInfo, err)
+	}
+
+	// a modified user-info is no longer sent with the received signature
+	modified := m
+	modified.UserInfo = &UserInfo{InternalID: "BB99999"}
+	if _, err := parser.Parse(modified.ToIncomingContext(context.Background())); err == nil {
+		t.Errorf("Parse() of a modified user-info error = nil, want rejected")
+	}
+	got, err := parser.Parse(modified.ToIncomingContext(context.Background(), WithSigningKeyring(keyring)))
+	if err != nil || got.UserInfo == nil || got.UserInfo.InternalID != "BB99999" {
+		t.Errorf("Parse() of a re-signed user-info = %+v, %v, want BB99999", got.UserInfo, err)
+	}
+
+	// cron jobs building Metadata themselves sign it
+	cron := Metadata{RequestId: "cron-1", UserInfo: &UserInfo{InternalID: "BB12345"}}
+	if got, err := parser.Parse(cron.ToIncomingContext(context.Background(), WithSigningKeyring(keyring))); err != nil || got.UserInfo == nil {
+		t.Errorf("Parse() of signed cron metadata = %+v, %v, want the user-info", got.UserInfo, err)
+	}
+}
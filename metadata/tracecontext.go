@@ -0,0 +1,129 @@
+package metadata
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"go.elastic.co/apm/module/apmhttp/v2"
+	"go.elastic.co/apm/v2"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	MetadataTraceparent = "traceparent"  // Ex: 00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01
+	MetadataTracestate  = "tracestate"   // Ex: es=s:1,vendor=value
+	MetadataB3          = "b3"           // Ex: 0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-1
+	MetadataB3TraceId   = "x-b3-traceid" // Ex: 0af7651916cd43dd8448eb211c80319c
+	MetadataB3SpanId    = "x-b3-spanid"  // Ex: b7ad6b7169203331
+	MetadataB3Sampled   = "x-b3-sampled" // Ex: 1
+	MetadataB3Flags     = "x-b3-flags"   // Ex: 1, debug implies sampled
+)
+
+var errInvalidB3 = errors.New("invalid b3 header")
+
+// TraceContextFromMD returns the trace context sent in md, from the W3C traceparent
+// and tracestate, or else from the B3 single header, or else from the B3 multi headers.
+// Invalid headers are ignored as the W3C recommends, the request then starts a new trace.
+func TraceContextFromMD(md metadata.MD) (apm.TraceContext, bool) {
+	if v := md.Get(MetadataTraceparent); len(v) > 0 {
+		if tc, err := apmhttp.ParseTraceparentHeader(v[0]); err == nil {
+			if state, err := apmhttp.ParseTracestateHeader(md.Get(MetadataTracestate)...); err == nil && state.Validate() == nil {
+				tc.State = state
+			}
+			return tc, true
+		}
+	}
+	if v := md.Get(MetadataB3); len(v) > 0 {
+		if tc, err := ParseB3(v[0]); err == nil {
+			return tc, true
+		}
+	}
+	if traceId := md.Get(MetadataB3TraceId); len(traceId) > 0 {
+		spanId, sampled := "", ""
+		if v := md.Get(MetadataB3SpanId); len(v) > 0 {
+			spanId = v[0]
+		}
+		if v := md.Get(MetadataB3Flags); len(v) > 0 && v[0] == "1" {
+			sampled = "d"
+		} else if v := md.Get(MetadataB3Sampled); len(v) > 0 {
+			sampled = v[0]
+		}
+		if tc, err := parseB3(traceId[0], spanId, sampled); err == nil {
+			return tc, true
+		}
+	}
+	return apm.TraceContext{}, false
+}
+
+// ParseB3 parses the B3 single header, "{trace-id}-{span-id}-{sampled}-{parent-span-id}"
+// where the sampled and parent span id are optional. A 64-bit trace id is left padded with zeros.
+func ParseB3(value string) (apm.TraceContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return apm.TraceContext{}, errInvalidB3
+	}
+	sampled := ""
+	if len(parts) > 2 {
+		sampled = parts[2]
+	}
+	return parseB3(parts[0], parts[1], sampled)
+}
+
+func parseB3(traceId, spanId, sampled string) (apm.TraceContext, error) {
+	var tc apm.TraceContext
+	if len(traceId) == 16 {
+		traceId = strings.Repeat("0", 16) + traceId
+	}
+	if len(traceId) != 32 || len(spanId) != 16 {
+		return tc, errInvalidB3
+	}
+	if _, err := hex.Decode(tc.Trace[:], []byte(traceId)); err != nil || tc.Trace.Validate() != nil {
+		return tc, errInvalidB3
+	}
+	if _, err := hex.Decode(tc.Span[:], []byte(spanId)); err != nil || tc.Span.Validate() != nil {
+		return tc, errInvalidB3
+	}
+	switch strings.ToLower(sampled) {
+	case "1", "d", "true":
+		tc.Options = tc.Options.WithRecorded(true)
+	case "", "0", "false":
+	default:
+		return tc, errInvalidB3
+	}
+	return tc, nil
+}
+
+// SetTraceContext sets the traceparent, tracestate and B3 single header of tc on md,
+// replacing the trace context headers already in md.
+func SetTraceContext(md metadata.MD, tc apm.TraceContext) {
+	for _, k := range []string{MetadataTracestate, MetadataB3TraceId, MetadataB3SpanId, MetadataB3Sampled, MetadataB3Flags} {
+		md.Delete(k)
+	}
+	md.Set(MetadataTraceparent, apmhttp.FormatTraceparentHeader(tc))
+	if state := tc.State.String(); state != "" {
+		md.Set(MetadataTracestate, state)
+	}
+	sampled := "0"
+	if tc.Options.Recorded() {
+		sampled = "1"
+	}
+	md.Set(MetadataB3, tc.Trace.String()+"-"+tc.Span.String()+"-"+sampled)
+}
+
+// TraceContextFromContext returns the trace context to propagate to the next hop: the one
+// of the active APM span or transaction, or else the one parsed from the incoming metadata.
+func TraceContextFromContext(ctx context.Context) (apm.TraceContext, bool) {
+	if span := apm.SpanFromContext(ctx); span != nil {
+		return span.TraceContext(), true
+	}
+	if tx := apm.TransactionFromContext(ctx); tx != nil {
+		return tx.TraceContext(), true
+	}
+	if m, ok := FromContext(ctx); ok && m.TraceContext != nil {
+		return *m.TraceContext, true
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	return TraceContextFromMD(md)
+}
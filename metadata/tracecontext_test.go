@@ -0,0 +1,107 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+
+	"go.elastic.co/apm/v2"
+	"go.elastic.co/apm/v2/transport/transporttest"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestTraceContextFromMD(t *testing.T) {
+	tests := []struct {
+		name        string
+		md          metadata.MD
+		wantOk      bool
+		wantTrace   string
+		wantSpan    string
+		wantSampled bool
+		wantState   string
+	}{
+		{
+			name:        "traceparent",
+			md:          metadata.Pairs(MetadataTraceparent, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", MetadataTracestate, "vendor=value"),
+			wantOk:      true,
+			wantTrace:   "0af7651916cd43dd8448eb211c80319c",
+			wantSpan:    "b7ad6b7169203331",
+			wantSampled: true,
+			wantState:   "vendor=value",
+		},
+		{
+			name:      "traceparent wins over b3",
+			md:        metadata.Pairs(MetadataTraceparent, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00", MetadataB3, "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1"),
+			wantOk:    true,
+			wantTrace: "0af7651916cd43dd8448eb211c80319c",
+			wantSpan:  "b7ad6b7169203331",
+		},
+		{
+			name:        "invalid traceparent falls back to b3",
+			md:          metadata.Pairs(MetadataTraceparent, "00-00000000000000000000000000000000-b7ad6b7169203331-01", MetadataB3, "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-d-05e3ac9a4f6e3b90"),
+			wantOk:      true,
+			wantTrace:   "80f198ee56343ba864fe8b2a57d3eff7",
+			wantSpan:    "e457b5a2e4d86bd1",
+			wantSampled: true,
+		},
+		{
+			name:      "b3 multi headers with 64-bit trace id",
+			md:        metadata.Pairs(MetadataB3TraceId, "64fe8b2a57d3eff7", MetadataB3SpanId, "e457b5a2e4d86bd1", MetadataB3Sampled, "0"),
+			wantOk:    true,
+			wantTrace: "000000000000000064fe8b2a57d3eff7",
+			wantSpan:  "e457b5a2e4d86bd1",
+		},
+		{
+			name: "b3 sampling only",
+			md:   metadata.Pairs(MetadataB3, "1"),
+		},
+		{
+			name: "none",
+			md:   metadata.Pairs(MetadataTraceId, "trace-1"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc, ok := TraceContextFromMD(tt.md)
+			if ok != tt.wantOk {
+				t.Fatalf("TraceContextFromMD() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !ok {
+				return
+			}
+			if tc.Trace.String() != tt.wantTrace || tc.Span.String() != tt.wantSpan || tc.Options.Recorded() != tt.wantSampled || tc.State.String() != tt.wantState {
+				t.Errorf("TraceContextFromMD() = %s %s %v %q, want %s %s %v %q", tc.Trace, tc.Span, tc.Options.Recorded(), tc.State.String(), tt.wantTrace, tt.wantSpan, tt.wantSampled, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestTraceContext_traceId(t *testing.T) {
+	md := metadata.Pairs(MetadataTraceparent, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	m, err := NewParser().Parse(metadata.NewIncomingContext(context.Background(), md))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.TraceId != "0af7651916cd43dd8448eb211c80319c" || m.TraceContext == nil {
+		t.Fatalf("Parse() trace-id = %s, trace context = %v", m.TraceId, m.TraceContext)
+	}
+
+	out := m.ToOutgoingMD()
+	if got := out.Get(MetadataTraceparent); len(got) != 1 || got[0] != md.Get(MetadataTraceparent)[0] {
+		t.Errorf("ToOutgoingMD() traceparent = %v", got)
+	}
+	if got := out.Get(MetadataB3); len(got) != 1 || got[0] != "0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-1" {
+		t.Errorf("ToOutgoingMD() b3 = %v", got)
+	}
+
+	tracer, err := apm.NewTracerOptions(apm.TracerOptions{Transport: transporttest.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tracer.Close()
+	tx := tracer.StartTransaction("test", "request")
+	defer tx.End()
+	ctx := apm.ContextWithTransaction(context.Background(), tx)
+	if got := InitiateTraceId(ctx).Value(MetadataTraceId); got != tx.TraceContext().Trace.String() {
+		t.Errorf("InitiateTraceId() with APM transaction = %v, want %s", got, tx.TraceContext().Trace)
+	}
+}
@@ -0,0 +1,174 @@
+package microservice
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrLimitExceeded is returned when the concurrency limit is reached and no slot frees up in time.
+var ErrLimitExceeded = errors.New("concurrency limit exceeded")
+
+// LimiterConfig configures an adaptive (AIMD) concurrency limiter.
+//
+// The limit grows by one every time a full window of calls completes below
+// LatencyThreshold, and is multiplied by BackoffRatio when a call is slower
+// than LatencyThreshold or fails.
+type LimiterConfig struct {
+	Name             string
+	InitialLimit     int
+	MinLimit         int
+	MaxLimit         int
+	LatencyThreshold time.Duration
+	BackoffRatio     float64
+	// MaxWait is how long a call waits for a free slot; zero rejects immediately
+	MaxWait time.Duration
+	// IsDropped reports whether an error should decrease the limit, by default every error does
+	IsDropped func(err error) bool
+}
+
+type Limiter struct {
+	config   LimiterConfig
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	released chan struct{}
+}
+
+func DefaultLimiterSetting(name string, latencyThreshold time.Duration) *LimiterConfig {
+	return &LimiterConfig{
+		Name:             name,
+		InitialLimit:     20,
+		MinLimit:         1,
+		MaxLimit:         200,
+		LatencyThreshold: latencyThreshold,
+		BackoffRatio:     0.9,
+	}
+}
+
+func NewConcurrencyLimiter(config *LimiterConfig) *Limiter {
+	defConfig := DefaultLimiterSetting("default-limiter", time.Second)
+	if config == nil {
+		config = defConfig
+	}
+	if config.Name == "" {
+		config.Name = defConfig.Name
+	}
+	if config.MinLimit <= 0 {
+		config.MinLimit = defConfig.MinLimit
+	}
+	if config.MaxLimit <= 0 {
+		config.MaxLimit = defConfig.MaxLimit
+	}
+	if config.MaxLimit < config.MinLimit {
+		config.MaxLimit = config.MinLimit
+	}
+	if config.InitialLimit <= 0 {
+		config.InitialLimit = defConfig.InitialLimit
+	}
+	if config.LatencyThreshold <= 0 {
+		config.LatencyThreshold = defConfig.LatencyThreshold
+	}
+	if config.BackoffRatio <= 0 || config.BackoffRatio >= 1 {
+		config.BackoffRatio = defConfig.BackoffRatio
+	}
+	if config.IsDropped == nil {
+		config.IsDropped = defaultIsDropped
+	}
+
+	l := &Limiter{
+		config:   *config,
+		released: make(chan struct{}),
+	}
+	l.limit = l.clamp(float64(config.InitialLimit))
+	return l
+}
+
+// Execute runs req when a slot is available and adjusts the limit from its latency and error.
+func (l *Limiter) Execute(ctx context.Context, req func() (interface{}, error)) (interface{}, error) {
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	res, err := req()
+	l.release(time.Since(start), err)
+
+	return res, err
+}
+
+// Name returns the limiter name.
+func (l *Limiter) Name() string {
+	return l.config.Name
+}
+
+// Limit returns the current concurrency limit.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// InFlight returns the number of calls currently running.
+func (l *Limiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+func (l *Limiter) acquire(ctx context.Context) error {
+	var timeout <-chan time.Time
+	if l.config.MaxWait > 0 {
+		timer := time.NewTimer(l.config.MaxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for {
+		l.mu.Lock()
+		if l.inFlight < int(l.limit) {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		released := l.released
+		l.mu.Unlock()
+
+		if timeout == nil {
+			return ErrLimitExceeded
+		}
+		select {
+		case <-released:
+		case <-timeout:
+			return ErrLimitExceeded
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (l *Limiter) release(latency time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	if (err != nil && l.config.IsDropped(err)) || latency > l.config.LatencyThreshold {
+		l.limit = l.clamp(l.limit * l.config.BackoffRatio)
+	} else {
+		l.limit = l.clamp(l.limit + 1/math.Floor(l.limit))
+	}
+
+	// wake up every waiting call, they race for the freed slot
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+func (l *Limiter) clamp(limit float64) float64 {
+	return math.Max(float64(l.config.MinLimit), math.Min(float64(l.config.MaxLimit), limit))
+}
+
+func defaultIsDropped(err error) bool {
+	return !defaultIsSuccessful(err)
+}
@@ -0,0 +1,83 @@
+package microservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Execute(t *testing.T) {
+	ok := func() (interface{}, error) { return nil, nil }
+	fail := func() (interface{}, error) { return nil, errors.New("unavailable") }
+	slow := func() (interface{}, error) {
+		time.Sleep(20 * time.Millisecond)
+		return nil, nil
+	}
+
+	tests := []struct {
+		name      string
+		calls     []func() (interface{}, error)
+		wantLimit int
+	}{
+		{
+			name:      "additive increase after a full window",
+			calls:     []func() (interface{}, error){ok, ok, ok, ok},
+			wantLimit: 5,
+		},
+		{
+			name:      "multiplicative decrease on error",
+			calls:     []func() (interface{}, error){fail},
+			wantLimit: 2,
+		},
+		{
+			name:      "multiplicative decrease on slow call",
+			calls:     []func() (interface{}, error){slow},
+			wantLimit: 2,
+		},
+		{
+			name:      "never below min limit",
+			calls:     []func() (interface{}, error){fail, fail, fail, fail, fail, fail},
+			wantLimit: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewConcurrencyLimiter(&LimiterConfig{
+				InitialLimit:     4,
+				LatencyThreshold: 10 * time.Millisecond,
+				BackoffRatio:     0.5,
+			})
+			for _, call := range tt.calls {
+				l.Execute(context.Background(), call)
+			}
+			if got := l.Limit(); got != tt.wantLimit {
+				t.Errorf("Limiter.Limit() = %v, want %v", got, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestLimiter_ExecuteRejectsWhenFull(t *testing.T) {
+	l := NewConcurrencyLimiter(&LimiterConfig{InitialLimit: 1, MaxLimit: 1, MaxWait: 50 * time.Millisecond})
+
+	started, done := make(chan struct{}), make(chan struct{})
+	go l.Execute(context.Background(), func() (interface{}, error) {
+		close(started)
+		<-done
+		return nil, nil
+	})
+	<-started
+
+	if _, err := l.Execute(context.Background(), func() (interface{}, error) { return nil, nil }); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("Limiter.Execute() error = %v, want %v", err, ErrLimitExceeded)
+	}
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		close(done)
+	}()
+	if _, err := l.Execute(context.Background(), func() (interface{}, error) { return nil, nil }); err != nil {
+		t.Errorf("Limiter.Execute() error = %v, want queued call to succeed", err)
+	}
+}
@@ -0,0 +1,150 @@
+package microservice
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"reflect"
+)
+
+// Key is a context key holding values of type T. It stores values under the same
+// contextKey as the untyped Ctx keys, so ctx.Value(CtxUserID) and KeyUserID.From(ctx)
+// read the same value while services migrate.
+type Key[T any] struct {
+	key contextKey
+}
+
+// NewKey creates a new Key named name, e.g. NewKey[string]("uid") is the typed CtxUserID.
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{key: contextKey(name)}
+}
+
+// With returns a copy of ctx holding v.
+func (k Key[T]) With(ctx context.Context, v T) context.Context {
+	return context.WithValue(ctx, k.key, v)
+}
+
+// From returns the value held by ctx, false when there is none or it is not a T.
+// Numbers of another type are converted when T is a number, e.g. the float64 or
+// json.Number of a JWT claim decoded from JSON for a Key[int64], as long as no
+// precision is lost. Likewise the []interface{} of a JSON array of strings is
+// converted for a Key[[]string].
+func (k Key[T]) From(ctx context.Context) (T, bool) {
+	raw := ctx.Value(k.key)
+	if v, ok := raw.(T); ok {
+		return v, true
+	}
+	if v, ok := convertStrings[T](raw); ok {
+		return v, true
+	}
+	return convertNumber[T](raw)
+}
+
+// MustFrom returns the value held by ctx and panics when there is none.
+func (k Key[T]) MustFrom(ctx context.Context) T {
+	v, ok := k.From(ctx)
+	if !ok {
+		panic("microservice: context has no " + k.key.String())
+	}
+	return v
+}
+
+// convertStrings converts raw to T when T is []string and raw is a []interface{} of strings.
+func convertStrings[T any](raw interface{}) (T, bool) {
+	var zero T
+	items, ok := raw.([]interface{})
+	if !ok {
+		return zero, false
+	}
+	if _, ok := interface{}(zero).([]string); !ok {
+		return zero, false
+	}
+	res := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return zero, false
+		}
+		res = append(res, s)
+	}
+	return interface{}(res).(T), true
+}
+
+// convertNumber converts raw to T when both are numbers and the value fits T exactly.
+func convertNumber[T any](raw interface{}) (T, bool) {
+	var zero T
+	target := reflect.TypeOf(zero)
+	if target == nil || raw == nil || !isNumber(target.Kind()) {
+		return zero, false
+	}
+
+	if n, ok := raw.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			raw = i
+		} else if f, err := n.Float64(); err == nil {
+			raw = f
+		} else {
+			return zero, false
+		}
+	}
+	v := reflect.ValueOf(raw)
+	if !isNumber(v.Kind()) {
+		return zero, false
+	}
+	if isFloat(v.Kind()) && !isFloat(target.Kind()) && v.Float() != math.Trunc(v.Float()) {
+		return zero, false
+	}
+	if isUnsigned(target.Kind()) && ((isFloat(v.Kind()) && v.Float() < 0) || (v.Kind() <= reflect.Int64 && v.Int() < 0)) {
+		return zero, false
+	}
+
+	converted := v.Convert(target)
+	// converting back must give the same value, otherwise it overflowed
+	if !converted.Convert(v.Type()).Equal(v) {
+		return zero, false
+	}
+	return converted.Interface().(T), true
+}
+
+func isNumber(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func isUnsigned(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func (k Key[T]) String() string {
+	return k.key.String()
+}
+
+// typed keys of the Ctx keys, holding the same values
+var (
+	KeyACL            = NewKey[string](CtxACL.String())
+	KeyACLS           = NewKey[[]string](CtxACLS.String())
+	KeyUserID         = NewKey[string](CtxUserID.String())
+	KeyUserUUID       = NewKey[string](CtxUserUUID.String())
+	KeyDomain         = NewKey[string](CtxDomain.String())
+	KeyPhone          = NewKey[string](CtxPhone.String())
+	KeyEmail          = NewKey[string](CtxEmail.String())
+	KeyDomainName     = NewKey[string](CtxDomainName.String())
+	KeyTopic          = NewKey[string](CtxTopic.String())
+	KeyAudit          = NewKey[string](CtxAudit.String())
+	KeyDomainID       = NewKey[string](CtxDomainID.String())
+	KeyDomainType     = NewKey[string](CtxDomainType.String())
+	KeyExp            = NewKey[int64](CtxExp.String()) // unix time
+	KeyGroupName      = NewKey[string](CtxGroupName.String())
+	KeyRequestID      = NewKey[string](CtxRequestID.String())
+	KeyRequestUUID    = NewKey[string](CtxRequestUUID.String())
+	KeyRequestName    = NewKey[string](CtxRequestName.String())
+	KeySubName        = NewKey[string](CtxSubName.String())
+	KeyAuthTime       = NewKey[int64](CtxAuthTime.String()) // unix time
+	KeyBBGBBDAccess   = NewKey[[]string](CtxBBGBBDAccess.String())
+	KeyAccessAreas    = NewKey[[]string](CtxAccessAreas.String())
+	KeyAccessPools    = NewKey[[]string](CtxAccessPools.String())
+	KeySecurityPolicy = NewKey[string](CtxSecurityPolicy.String())
+)
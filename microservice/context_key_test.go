@@ -0,0 +1,66 @@
+package microservice
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	ctx := KeyUserID.With(context.Background(), "BB12345")
+	ctx = KeyAccessAreas.With(ctx, []string{"jakarta", "bali"})
+
+	if got, ok := KeyUserID.From(ctx); !ok || got != "BB12345" {
+		t.Errorf("KeyUserID.From() = %v, %v, want BB12345", got, ok)
+	}
+	if got := KeyAccessAreas.MustFrom(ctx); !reflect.DeepEqual(got, []string{"jakarta", "bali"}) {
+		t.Errorf("KeyAccessAreas.MustFrom() = %v", got)
+	}
+	if got, ok := KeyExp.From(ctx); ok || got != 0 {
+		t.Errorf("KeyExp.From() = %v, %v, want no value", got, ok)
+	}
+
+	// typed and untyped keys share values
+	if got := ctx.Value(CtxUserID); got != "BB12345" {
+		t.Errorf("ctx.Value(CtxUserID) = %v, want the value set with KeyUserID", got)
+	}
+	legacy := context.WithValue(context.Background(), CtxAuthTime, int64(1700000000))
+	if got, ok := KeyAuthTime.From(legacy); !ok || got != 1700000000 {
+		t.Errorf("KeyAuthTime.From() = %v, %v, want the value set with CtxAuthTime", got, ok)
+	}
+	jwtClaims := map[string]interface{}{}
+	json.Unmarshal([]byte(`{"exp":1700000000,"auth_time":1699990000}`), &jwtClaims)
+	claims := context.WithValue(context.Background(), CtxExp, jwtClaims["exp"])
+	if got, ok := KeyExp.From(claims); !ok || got != 1700000000 {
+		t.Errorf("KeyExp.From() of a float64 claim = %v, %v, want 1700000000", got, ok)
+	}
+	claims = context.WithValue(claims, CtxAuthTime, json.Number("1699990000"))
+	if got, ok := KeyAuthTime.From(claims); !ok || got != 1699990000 {
+		t.Errorf("KeyAuthTime.From() of a json.Number claim = %v, %v, want 1699990000", got, ok)
+	}
+	json.Unmarshal([]byte(`{"acls":["read","write"],"access_areas":["jakarta",1]}`), &jwtClaims)
+	claims = context.WithValue(claims, CtxACLS, jwtClaims["acls"])
+	if got, ok := KeyACLS.From(claims); !ok || !reflect.DeepEqual(got, []string{"read", "write"}) {
+		t.Errorf("KeyACLS.From() of a JSON array claim = %v, %v, want [read write]", got, ok)
+	}
+	claims = context.WithValue(claims, CtxAccessAreas, jwtClaims["access_areas"])
+	if _, ok := KeyAccessAreas.From(claims); ok {
+		t.Errorf("KeyAccessAreas.From() of a JSON array with a number = ok, want false")
+	}
+	fraction := context.WithValue(context.Background(), CtxExp, 1700000000.5)
+	if _, ok := KeyExp.From(fraction); ok {
+		t.Errorf("KeyExp.From() of a fractional value = ok, want false")
+	}
+	wrongType := context.WithValue(context.Background(), CtxExp, "1700000000")
+	if _, ok := KeyExp.From(wrongType); ok {
+		t.Errorf("KeyExp.From() of a string value = ok, want false")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustFrom() without value did not panic")
+		}
+	}()
+	KeyDomain.MustFrom(ctx)
+}
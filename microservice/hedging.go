@@ -0,0 +1,224 @@
+package microservice
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	hedgingMinSamples = 20
+	hedgingMaxTokens  = 10
+)
+
+// HedgingConfig configures request hedging.
+//
+// After Delay, or after the Percentile of observed latencies once enough calls
+// were seen, another attempt is started and the first success wins.
+type HedgingConfig struct {
+	Name string
+	// Delay before a hedged attempt is sent
+	Delay time.Duration
+	// Percentile of observed latencies used as delay instead of Delay, e.g. 0.95; zero disables it
+	Percentile float64
+	// MaxHedgeRatio caps hedged attempts as a ratio of calls, e.g. 0.1 hedges at most 10% of calls
+	MaxHedgeRatio float64
+	// MaxAttempts is the total number of attempts including the first one
+	MaxAttempts int
+}
+
+type Hedger struct {
+	config    HedgingConfig
+	mu        sync.Mutex
+	tokens    float64
+	latencies *prometheus.HistogramVec
+}
+
+func DefaultHedgingSetting(name string, delay time.Duration) *HedgingConfig {
+	return &HedgingConfig{
+		Name:          name,
+		Delay:         delay,
+		MaxHedgeRatio: 0.1,
+		MaxAttempts:   2,
+	}
+}
+
+func NewHedger(config *HedgingConfig) *Hedger {
+	defConfig := DefaultHedgingSetting("default-hedger", 100*time.Millisecond)
+	if config == nil {
+		config = defConfig
+	}
+	if config.Name == "" {
+		config.Name = defConfig.Name
+	}
+	if config.Delay <= 0 {
+		config.Delay = defConfig.Delay
+	}
+	if config.Percentile < 0 || config.Percentile >= 1 {
+		config.Percentile = 0
+	}
+	if config.MaxHedgeRatio <= 0 {
+		config.MaxHedgeRatio = defConfig.MaxHedgeRatio
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defConfig.MaxAttempts
+	}
+
+	return &Hedger{
+		config: *config,
+		// same buckets as the external request latency histograms, not registered
+		latencies: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "hedging_latency_seconds",
+			Help:    "Latency of successful hedged calls",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"key"}),
+	}
+}
+
+type hedgingResult struct {
+	attempt int
+	res     interface{}
+	err     error
+	latency time.Duration
+}
+
+// Execute runs req and, when it is slower than the hedging delay for key, runs it again
+// concurrently. The first successful result wins and the other attempts are cancelled;
+// their late successful results are passed to discard, which may be nil.
+// Every attempt's context is cancelled before Execute returns, so req must return a
+// result that stays usable without its context, e.g. a decoded reply.
+func (h *Hedger) Execute(ctx context.Context, key string, req func(ctx context.Context) (interface{}, error), discard func(interface{})) (interface{}, error) {
+	res, release, err := h.ExecuteWithRelease(ctx, key, req, discard)
+	release()
+	return res, err
+}
+
+// ExecuteWithRelease is Execute for results still read through the winning attempt's
+// context, e.g. a streamed response body. Only the losing attempts are cancelled before
+// it returns, the winning attempt's context stays alive until release is called.
+func (h *Hedger) ExecuteWithRelease(ctx context.Context, key string, req func(ctx context.Context) (interface{}, error), discard func(interface{})) (res interface{}, release context.CancelFunc, err error) {
+	h.deposit()
+
+	results := make(chan hedgingResult, h.config.MaxAttempts)
+	cancels := []context.CancelFunc{}
+	winner := -1
+	defer func() {
+		for i, cancel := range cancels {
+			if i != winner {
+				cancel()
+			}
+		}
+	}()
+	start := func() {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		attempt := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			begin := time.Now()
+			res, err := req(attemptCtx)
+			results <- hedgingResult{attempt: attempt, res: res, err: err, latency: time.Since(begin)}
+		}()
+	}
+
+	delay := h.delay(key)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	start()
+	pending := 1
+	for {
+		select {
+		case <-timer.C:
+			if len(cancels) < h.config.MaxAttempts && h.withdraw() {
+				start()
+				pending++
+				timer.Reset(delay)
+			}
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				h.observe(key, r.latency)
+				if pending > 0 && discard != nil {
+					go discardResults(results, pending, discard)
+				}
+				winner = r.attempt
+				return r.res, cancels[winner], nil
+			}
+			if pending == 0 {
+				return nil, func() {}, r.err
+			}
+		}
+	}
+}
+
+func discardResults(results <-chan hedgingResult, pending int, discard func(interface{})) {
+	for ; pending > 0; pending-- {
+		if r := <-results; r.err == nil {
+			discard(r.res)
+		}
+	}
+}
+
+// deposit earns MaxHedgeRatio of a hedge for every call.
+func (h *Hedger) deposit() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tokens = math.Min(hedgingMaxTokens, h.tokens+h.config.MaxHedgeRatio)
+}
+
+// withdraw spends a hedge when the budget allows it.
+func (h *Hedger) withdraw() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.tokens < 1 {
+		return false
+	}
+	h.tokens--
+	return true
+}
+
+func (h *Hedger) delay(key string) time.Duration {
+	if h.config.Percentile == 0 {
+		return h.config.Delay
+	}
+
+	m := &dto.Metric{}
+	if err := h.latencies.WithLabelValues(key).(prometheus.Histogram).Write(m); err != nil {
+		return h.config.Delay
+	}
+	if m.GetHistogram().GetSampleCount() < hedgingMinSamples {
+		return h.config.Delay
+	}
+	return histogramQuantile(m.GetHistogram(), h.config.Percentile)
+}
+
+func (h *Hedger) observe(key string, latency time.Duration) {
+	if h.config.Percentile == 0 {
+		return
+	}
+	h.latencies.WithLabelValues(key).Observe(latency.Seconds())
+}
+
+// histogramQuantile estimates the quantile q of a histogram by linear interpolation
+// within its bucket, as histogram_quantile does in PromQL.
+func histogramQuantile(hist *dto.Histogram, q float64) time.Duration {
+	rank := q * float64(hist.GetSampleCount())
+	lowerBound, lowerCount := 0.0, 0.0
+	for _, b := range hist.GetBucket() {
+		count := float64(b.GetCumulativeCount())
+		if count >= rank {
+			if count == lowerCount {
+				return time.Duration(b.GetUpperBound() * float64(time.Second))
+			}
+			seconds := lowerBound + (b.GetUpperBound()-lowerBound)*(rank-lowerCount)/(count-lowerCount)
+			return time.Duration(seconds * float64(time.Second))
+		}
+		lowerBound, lowerCount = b.GetUpperBound(), count
+	}
+	// beyond the last bucket, its upper bound is the best estimate
+	return time.Duration(lowerBound * float64(time.Second))
+}
@@ -0,0 +1,147 @@
+package microservice
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedger_Execute(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name         string
+		config       HedgingConfig
+		firstLatency time.Duration
+		firstErr     error
+		want         interface{}
+		wantErr      error
+		wantAttempts int32
+	}{
+		{
+			name:         "fast call is not hedged",
+			config:       HedgingConfig{Delay: 50 * time.Millisecond, MaxHedgeRatio: 1},
+			want:         0,
+			wantAttempts: 1,
+		},
+		{
+			name:         "slow call is hedged and the hedge wins",
+			config:       HedgingConfig{Delay: 10 * time.Millisecond, MaxHedgeRatio: 1},
+			firstLatency: time.Second,
+			want:         1,
+			wantAttempts: 2,
+		},
+		{
+			name:         "no hedge without budget",
+			config:       HedgingConfig{Delay: 10 * time.Millisecond, MaxHedgeRatio: 0.1},
+			firstLatency: 30 * time.Millisecond,
+			want:         0,
+			wantAttempts: 1,
+		},
+		{
+			name:         "error is returned when every attempt fails",
+			config:       HedgingConfig{Delay: 50 * time.Millisecond, MaxHedgeRatio: 1},
+			firstErr:     errFailed,
+			wantErr:      errFailed,
+			wantAttempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			var cancelled int32
+			h := NewHedger(&tt.config)
+			got, err := h.Execute(context.Background(), "key", func(ctx context.Context) (interface{}, error) {
+				attempt := atomic.AddInt32(&attempts, 1) - 1
+				if attempt > 0 {
+					return int(attempt), nil
+				}
+				select {
+				case <-time.After(tt.firstLatency):
+					return 0, tt.firstErr
+				case <-ctx.Done():
+					atomic.AddInt32(&cancelled, 1)
+					return nil, ctx.Err()
+				}
+			}, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Hedger.Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("Hedger.Execute() = %v, want %v", got, tt.want)
+			}
+			if n := atomic.LoadInt32(&attempts); n != tt.wantAttempts {
+				t.Errorf("Hedger.Execute() attempts = %v, want %v", n, tt.wantAttempts)
+			}
+			if tt.wantAttempts > 1 {
+				time.Sleep(10 * time.Millisecond)
+				if atomic.LoadInt32(&cancelled) != 1 {
+					t.Errorf("Hedger.Execute() did not cancel the losing attempt")
+				}
+			}
+		})
+	}
+}
+
+func TestHedger_Execute_cancelsWinner(t *testing.T) {
+	var winnerCtx context.Context
+	h := NewHedger(nil)
+	if _, err := h.Execute(context.Background(), "key", func(ctx context.Context) (interface{}, error) {
+		winnerCtx = ctx
+		return 1, nil
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if winnerCtx.Err() == nil {
+		t.Errorf("Hedger.Execute() left the winning attempt's context alive")
+	}
+}
+
+func TestHedger_ExecuteWithRelease(t *testing.T) {
+	var mu sync.Mutex
+	ctxs := []context.Context{}
+	h := NewHedger(&HedgingConfig{Delay: 10 * time.Millisecond, MaxHedgeRatio: 1})
+	got, release, err := h.ExecuteWithRelease(context.Background(), "key", func(ctx context.Context) (interface{}, error) {
+		mu.Lock()
+		attempt := len(ctxs)
+		ctxs = append(ctxs, ctx)
+		mu.Unlock()
+		if attempt == 0 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return attempt, nil
+	}, nil)
+	if err != nil || got != 1 {
+		t.Fatalf("Hedger.ExecuteWithRelease() = %v, %v, want 1", got, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if ctxs[0].Err() == nil {
+		t.Errorf("Hedger.ExecuteWithRelease() did not cancel the losing attempt")
+	}
+	if ctxs[1].Err() != nil {
+		t.Errorf("Hedger.ExecuteWithRelease() cancelled the winning attempt before release")
+	}
+	release()
+	if ctxs[1].Err() == nil {
+		t.Errorf("release() did not cancel the winning attempt")
+	}
+}
+
+func TestHedger_delay(t *testing.T) {
+	h := NewHedger(&HedgingConfig{Delay: time.Second, Percentile: 0.9})
+	if got := h.delay("key"); got != time.Second {
+		t.Errorf("Hedger.delay() = %v, want %v before enough samples", got, time.Second)
+	}
+	for i := 1; i <= 100; i++ {
+		h.observe("key", time.Duration(i)*time.Millisecond)
+	}
+	if got := h.delay("key"); got != 90*time.Millisecond {
+		t.Errorf("Hedger.delay() = %v, want %v", got, 90*time.Millisecond)
+	}
+}
@@ -38,6 +38,12 @@ func (c *Client) Close() error {
 	return c.Conn.Close()
 }
 
+// Ping checks the connection to pubsub by looking the topic up, e.g. for health checks.
+func (c *Client) Ping(ctx context.Context, topicName string) error {
+	_, err := c.Conn.Topic(topicName).Exists(ctx)
+	return err
+}
+
 func (c *Client) PublishMessage(ctx context.Context, topicName string, message []byte) error {
 	topic, err := CheckAndCreateTopic(ctx, topicName, c.Conn)
 	if err != nil {